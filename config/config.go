@@ -2,96 +2,478 @@ package config
 
 import (
 	"fmt" // Importa fmt para usar fmt.Errorf
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"precio-bcv-go/logging"
+	"precio-bcv-go/utils"
 
 	"github.com/joho/godotenv"
 )
 
 // Config guarda las variables de configuración de la aplicación
 type Config struct {
-	Port           string
-	MongoDBURI     string
-	DatabaseName   string
-	CollectionName string
-	WhatsAppAPIURL string 
-	WhatsAppToNumber string
+	Port                     string
+	StorageBackend           string // "mongo" (por defecto), "memory" o "bolt"
+	BoltDBPath               string
+	MongoDBURI               string
+	DatabaseName             string
+	CollectionName           string
+	PlansCollectionName      string
+	QuarantineCollectionName string // Colección de tasas retenidas por la verificación de variación diaria.
+	APIKeysCollectionName    string
+	PlansFile                string             // Archivo JSON opcional con el catálogo inicial de planes.
+	TaxProfilesFile          string             // Archivo JSON opcional con los perfiles de impuestos; reemplaza a los incluidos.
+	MoneyRounding            utils.RoundingMode // Regla de redondeo de los montos a céntimos: half_up (por defecto) o half_even.
+	AdminAPIToken            string             // Token requerido por las rutas /admin; si está vacío, esas rutas quedan deshabilitadas.
+	WhatsAppAPIURL           string
+	WhatsAppToNumber         string
+
+	// Canales de alerta opcionales; cada uno se habilita al configurar sus variables.
+	TelegramBotToken string
+	TelegramChatID   string
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPFrom         string
+	SMTPTo           []string
+	SlackWebhookURL  string
+	AlertWebhookURL  string
+
+	// Avisos a suscriptores cuando se publica una nueva tasa.
+	RateSubscriberWhatsAppNumbers []string
+	RateSubscriberWebhookURL      string
+	LargeMoveThresholdPercent     float64 // Variación (en %) a partir de la cual el cambio también se alerta a los canales de alerta.
+
+	// Reintentos del scrapeo: intentos inmediatos con backoff y reintentos programados tras un fallo.
+	ScrapeMaxAttempts    int
+	ScrapeInitialBackoff time.Duration
+	ScrapeMaxBackoff     time.Duration
+	ScrapeRetryInterval  time.Duration
+	ScrapeMaxFollowUps   int
+
+	// Fuentes de tasas, en orden de prioridad, y tolerancia (en %) antes de marcar un desacuerdo entre ellas.
+	RateSources                []string // "bcv_page", "bcv_xls" y/o "json".
+	BCVPageURL                 string
+	BCVBulletinURL             string // URL del boletín XLS "tipo de cambio" del BCV.
+	RateJSONURL                string
+	RateJSONRatesField         string // Ruta con puntos al objeto moneda→tasa (ej. "data.rates").
+	RateJSONDateField          string // Ruta con puntos a la fecha valor; opcional.
+	RateSourceTolerancePercent float64
+
+	// Variación diaria máxima (en %) aceptada respecto a la última tasa guardada; por encima, la tasa queda en cuarentena.
+	MaxDailyChangePercent float64
+
+	// Claves de API de los clientes. Si APIKeyRequired es false, las peticiones sin clave siguen siendo públicas,
+	// pero las que envían una clave se identifican y se limitan igual. Los límites se cuentan por instancia
+	// y se reinician al reiniciar el proceso.
+	APIKeyRequired             bool
+	APIKeyDefaultRatePerMinute float64 // Límite por defecto de las claves nuevas (token bucket).
+	APIKeyDefaultBurst         int
+	APIKeyDefaultDailyQuota    int // 0 = sin límite diario.
+
+	// Política CORS de la API. Un origen "*" no puede combinarse con credenciales.
+	CORSAllowedOrigins   []string
+	CORSAllowedHeaders   []string
+	CORSAllowedMethods   []string
+	CORSAllowCredentials bool
+	CORSMaxAgeSeconds    int // Tiempo (en segundos) que el navegador guarda la respuesta preflight; 0 = no se envía.
+
+	// Servidor HTTP: timeouts de cada conexión y tiempo máximo para cerrar ordenadamente al recibir SIGINT/SIGTERM.
+	HTTPReadTimeout     time.Duration
+	HTTPWriteTimeout    time.Duration
+	HTTPIdleTimeout     time.Duration
+	ShutdownGracePeriod time.Duration
+
+	// Logs estructurados: nivel mínimo y formato ("text" o "json").
+	LogLevel  slog.Level
+	LogFormat string
+
+	// Antigüedad máxima de la fecha valor de la tasa actual para que /readyz reporte el servicio como listo;
+	// por encima, las respuestas de la API también la marcan como desactualizada ("stale").
+	ReadinessMaxRateAge time.Duration
+
+	// Trazas de OpenTelemetry: exportador ("none" u "otlp"), destino OTLP/HTTP y fracción de trazas muestreadas.
+	// Con OTLPEndpoint vacío, el exportador usa las variables estándar OTEL_EXPORTER_OTLP_* o su valor por defecto.
+	TracingExporter    string
+	TracingServiceName string
+	OTLPEndpoint       string // host:puerto del colector (ej. "localhost:4318").
+	OTLPInsecure       bool   // true para enviar por HTTP sin TLS.
+	TracingSampleRatio float64
 }
 
 // LoadConfig carga las variables de entorno desde un archivo .env.
 // Retorna un puntero a Config si todo es exitoso, o un error si alguna variable requerida falta.
 func LoadConfig() (*Config, error) {
 	// Obtener la ruta completa del archivo actual (config.go)
-	_, currentFilePath, _, _ := runtime.Caller(0) 
+	_, currentFilePath, _, _ := runtime.Caller(0)
 	configDir := filepath.Dir(currentFilePath)
 
 	// Construir la ruta al archivo .env, asumiendo que está en la raíz del proyecto (un nivel arriba de 'config' dir).
 	envFilePath := filepath.Join(configDir, "..", ".env")
 
-	log.Printf("Intentando cargar .env desde: %s\n", envFilePath)
+	slog.Debug("Intentando cargar .env", "path", envFilePath)
 
 	loadEnvErr := godotenv.Load(envFilePath) // Renombrado: 'err' -> 'loadEnvErr'
 	if loadEnvErr != nil {
-		log.Printf("Advertencia: No se pudo cargar el archivo .env desde %s. Usando variables de entorno del sistema o valores por defecto. Error: %v", envFilePath, loadEnvErr)
+		slog.Warn("No se pudo cargar el archivo .env. Usando variables de entorno del sistema o valores por defecto.", "path", envFilePath, "error", loadEnvErr)
 	} else {
-		log.Println(".env cargado exitosamente.")
+		slog.Info(".env cargado exitosamente.", "path", envFilePath)
 	}
 
 	// Obtener cada variable de entorno requerida.
 	// Si alguna variable no se encuentra o está vacía, se retorna un error fatal.
-	appPort, getPortErr := getRequiredEnv("PORT") 
+	appPort, getPortErr := getRequiredEnv("PORT")
 	if getPortErr != nil {
 		return nil, fmt.Errorf("variable de entorno faltante: %w", getPortErr)
 	}
 
-	dbURI, getDBURIErr := getRequiredEnv("MONGODB_URI")
-	if getDBURIErr != nil {
-		return nil, fmt.Errorf("variable de entorno faltante: %w", getDBURIErr)
+	// El backend de almacenamiento es opcional; por defecto se usa MongoDB.
+	storageBackend := getEnvOrDefault("STORAGE_BACKEND", "mongo")
+	boltDBPath := getEnvOrDefault("BOLT_DB_PATH", "bcv.db")
+
+	var dbURI, dbName, collectionName string
+	switch storageBackend {
+	case "mongo":
+		// Las variables de MongoDB solo son requeridas cuando se usa ese backend.
+		var getDBURIErr, getDBNameErr, getCollectionNameErr error
+		dbURI, getDBURIErr = getRequiredEnv("MONGODB_URI")
+		if getDBURIErr != nil {
+			return nil, fmt.Errorf("variable de entorno faltante: %w", getDBURIErr)
+		}
+
+		dbName, getDBNameErr = getRequiredEnv("DATABASE_NAME")
+		if getDBNameErr != nil {
+			return nil, fmt.Errorf("variable de entorno faltante: %w", getDBNameErr)
+		}
+
+		collectionName, getCollectionNameErr = getRequiredEnv("COLLECTION_NAME")
+		if getCollectionNameErr != nil {
+			return nil, fmt.Errorf("variable de entorno faltante: %w", getCollectionNameErr)
+		}
+	case "memory", "bolt":
+	default:
+		return nil, fmt.Errorf("valor inválido para STORAGE_BACKEND: '%s' (use mongo, memory o bolt)", storageBackend)
+	}
+
+	// --- CANALES DE ALERTA ---
+	// Todos son opcionales: un canal sin configurar simplemente no recibe alertas.
+	whatsAppAPIURL := getEnvOrDefault("WHATSAPP_API_URL", "")
+	whatsAppToNumber := getEnvOrDefault("WHATSAPP_TO_NUMBER", "")
+
+	// --- AVISOS DE CAMBIO DE TASA ---
+	largeMoveThresholdPercent, getThresholdErr := getEnvFloat("LARGE_MOVE_THRESHOLD_PERCENT", 5)
+	if getThresholdErr != nil {
+		return nil, getThresholdErr
+	}
+
+	// --- REINTENTOS DEL SCRAPEO ---
+	scrapeMaxAttempts, getAttemptsErr := getEnvInt("SCRAPE_MAX_ATTEMPTS", 4)
+	if getAttemptsErr != nil {
+		return nil, getAttemptsErr
+	}
+	scrapeInitialBackoff, getInitialBackoffErr := getEnvDuration("SCRAPE_INITIAL_BACKOFF", 2*time.Second)
+	if getInitialBackoffErr != nil {
+		return nil, getInitialBackoffErr
+	}
+	scrapeMaxBackoff, getMaxBackoffErr := getEnvDuration("SCRAPE_MAX_BACKOFF", 30*time.Second)
+	if getMaxBackoffErr != nil {
+		return nil, getMaxBackoffErr
+	}
+	scrapeRetryInterval, getRetryIntervalErr := getEnvDuration("SCRAPE_RETRY_INTERVAL", 15*time.Minute)
+	if getRetryIntervalErr != nil {
+		return nil, getRetryIntervalErr
+	}
+	scrapeMaxFollowUps, getMaxFollowUpsErr := getEnvInt("SCRAPE_MAX_FOLLOWUPS", 8)
+	if getMaxFollowUpsErr != nil {
+		return nil, getMaxFollowUpsErr
 	}
 
-	dbName, getDBNameErr := getRequiredEnv("DATABASE_NAME")
-	if getDBNameErr != nil {
-		return nil, fmt.Errorf("variable de entorno faltante: %w", getDBNameErr)
+	// --- FUENTES DE TASAS ---
+	rateSources := getEnvList("RATE_SOURCES")
+	if len(rateSources) == 0 {
+		rateSources = []string{"bcv_page"}
+	}
+	rateSourceTolerancePercent, getToleranceErr := getEnvFloat("RATE_SOURCE_TOLERANCE_PERCENT", 0.5)
+	if getToleranceErr != nil {
+		return nil, getToleranceErr
 	}
 
-	collectionName, getCollectionNameErr := getRequiredEnv("COLLECTION_NAME") 
-	if getCollectionNameErr != nil {
-		return nil, fmt.Errorf("variable de entorno faltante: %w", getCollectionNameErr)
+	// --- VERIFICACIÓN DE TASAS NUEVAS ---
+	maxDailyChangePercent, getMaxDailyChangeErr := getEnvFloat("MAX_DAILY_CHANGE_PERCENT", 10)
+	if getMaxDailyChangeErr != nil {
+		return nil, getMaxDailyChangeErr
 	}
 
-	// --- OBTENER LAS NUEVAS VARIABLES DE ENTORNO ---
-	whatsAppAPIURL, getWhatsAppAPIURLErr := getRequiredEnv("WHATSAPP_API_URL")
-	if getWhatsAppAPIURLErr != nil {
-		// Considera si quieres que esto sea un error fatal o solo una advertencia.
-		// Para una alerta, probablemente quieras que sea requerido.
-		return nil, fmt.Errorf("variable de entorno faltante: %w", getWhatsAppAPIURLErr)
+	// --- CLAVES DE API ---
+	apiKeyRequired, getAPIKeyRequiredErr := getEnvBool("API_KEY_REQUIRED", false)
+	if getAPIKeyRequiredErr != nil {
+		return nil, getAPIKeyRequiredErr
+	}
+	apiKeyDefaultRatePerMinute, getRatePerMinuteErr := getEnvFloat("API_KEY_DEFAULT_RATE_PER_MINUTE", 60)
+	if getRatePerMinuteErr != nil {
+		return nil, getRatePerMinuteErr
+	}
+	apiKeyDefaultBurst, getBurstErr := getEnvInt("API_KEY_DEFAULT_BURST", 20)
+	if getBurstErr != nil {
+		return nil, getBurstErr
+	}
+	apiKeyDefaultDailyQuota, getDailyQuotaErr := getEnvInt("API_KEY_DEFAULT_DAILY_QUOTA", 10000)
+	if getDailyQuotaErr != nil {
+		return nil, getDailyQuotaErr
 	}
 
-	whatsAppToNumber, getWhatsAppToNumberErr := getRequiredEnv("WHATSAPP_TO_NUMBER")
-	if getWhatsAppToNumberErr != nil {
-		return nil, fmt.Errorf("variable de entorno faltante: %w", getWhatsAppToNumberErr)
+	// --- CORS ---
+	// Por defecto se permite cualquier origen sin credenciales, con los métodos que usan las rutas /admin
+	// y las cabeceras de autenticación (token de administración y clave de API).
+	corsAllowedOrigins := getEnvList("CORS_ALLOWED_ORIGINS")
+	if len(corsAllowedOrigins) == 0 {
+		corsAllowedOrigins = []string{"*"}
+	}
+	corsAllowedHeaders := getEnvList("CORS_ALLOWED_HEADERS")
+	if len(corsAllowedHeaders) == 0 {
+		corsAllowedHeaders = []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID"}
+	}
+	corsAllowedMethods := getEnvList("CORS_ALLOWED_METHODS")
+	if len(corsAllowedMethods) == 0 {
+		corsAllowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	}
+	corsAllowCredentials, getAllowCredentialsErr := getEnvBool("CORS_ALLOW_CREDENTIALS", false)
+	if getAllowCredentialsErr != nil {
+		return nil, getAllowCredentialsErr
+	}
+	// Los navegadores rechazan "Access-Control-Allow-Origin: *" con credenciales; se exige listar los orígenes.
+	if corsAllowCredentials {
+		for _, corsOrigin := range corsAllowedOrigins {
+			if corsOrigin == "*" {
+				return nil, fmt.Errorf("CORS_ALLOW_CREDENTIALS=true requiere listar los orígenes permitidos en CORS_ALLOWED_ORIGINS (no se admite '*')")
+			}
+		}
+	}
+	corsMaxAgeSeconds, getMaxAgeErr := getEnvInt("CORS_MAX_AGE", 600)
+	if getMaxAgeErr != nil {
+		return nil, getMaxAgeErr
+	}
+	if corsMaxAgeSeconds < 0 {
+		return nil, fmt.Errorf("valor inválido para 'CORS_MAX_AGE': %d no puede ser negativo", corsMaxAgeSeconds)
+	}
+
+	// --- SERVIDOR HTTP ---
+	httpReadTimeout, getReadTimeoutErr := getEnvDuration("HTTP_READ_TIMEOUT", 10*time.Second)
+	if getReadTimeoutErr != nil {
+		return nil, getReadTimeoutErr
+	}
+	httpWriteTimeout, getWriteTimeoutErr := getEnvDuration("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	if getWriteTimeoutErr != nil {
+		return nil, getWriteTimeoutErr
+	}
+	httpIdleTimeout, getIdleTimeoutErr := getEnvDuration("HTTP_IDLE_TIMEOUT", 120*time.Second)
+	if getIdleTimeoutErr != nil {
+		return nil, getIdleTimeoutErr
+	}
+	shutdownGracePeriod, getGracePeriodErr := getEnvDuration("SHUTDOWN_GRACE_PERIOD", 20*time.Second)
+	if getGracePeriodErr != nil {
+		return nil, getGracePeriodErr
+	}
+
+	// --- MONTOS ---
+	moneyRounding, parseRoundingErr := utils.ParseRoundingMode(getEnvOrDefault("MONEY_ROUNDING", string(utils.RoundHalfUp)))
+	if parseRoundingErr != nil {
+		return nil, fmt.Errorf("valor inválido para MONEY_ROUNDING: %w", parseRoundingErr)
+	}
+
+	// --- SONDAS DE SALUD ---
+	// El BCV no publica los fines de semana ni los feriados, por lo que el valor por defecto cubre un fin de semana largo.
+	readinessMaxRateAge, getMaxRateAgeErr := getEnvDuration("READINESS_MAX_RATE_AGE", 96*time.Hour)
+	if getMaxRateAgeErr != nil {
+		return nil, getMaxRateAgeErr
+	}
+
+	// --- LOGS ---
+	logLevel, parseLevelErr := logging.ParseLevel(getEnvOrDefault("LOG_LEVEL", "info"))
+	if parseLevelErr != nil {
+		return nil, parseLevelErr
+	}
+	logFormat := strings.ToLower(getEnvOrDefault("LOG_FORMAT", logging.FormatText))
+	if logFormat != logging.FormatText && logFormat != logging.FormatJSON {
+		return nil, fmt.Errorf("valor inválido para LOG_FORMAT: '%s' (use text o json)", logFormat)
+	}
+
+	// --- TRAZAS ---
+	tracingExporter := strings.ToLower(getEnvOrDefault("TRACING_EXPORTER", "none"))
+	if tracingExporter != "none" && tracingExporter != "otlp" {
+		return nil, fmt.Errorf("valor inválido para TRACING_EXPORTER: '%s' (use none u otlp)", tracingExporter)
+	}
+	otlpInsecure, getOTLPInsecureErr := getEnvBool("OTLP_INSECURE", false)
+	if getOTLPInsecureErr != nil {
+		return nil, getOTLPInsecureErr
+	}
+	tracingSampleRatio, getSampleRatioErr := getEnvFloat("TRACING_SAMPLE_RATIO", 1)
+	if getSampleRatioErr != nil {
+		return nil, getSampleRatioErr
+	}
+	if tracingSampleRatio < 0 || tracingSampleRatio > 1 {
+		return nil, fmt.Errorf("valor inválido para TRACING_SAMPLE_RATIO: %v (debe estar entre 0 y 1)", tracingSampleRatio)
 	}
 
 	// Si todas las variables requeridas se encuentran y tienen valor, se retorna la configuración final.
 	return &Config{
-		Port:           appPort,
-		MongoDBURI:     dbURI,
-		DatabaseName:   dbName,
-		CollectionName: collectionName,
+		Port:                     appPort,
+		StorageBackend:           storageBackend,
+		BoltDBPath:               boltDBPath,
+		MongoDBURI:               dbURI,
+		DatabaseName:             dbName,
+		CollectionName:           collectionName,
+		PlansCollectionName:      getEnvOrDefault("PLANS_COLLECTION_NAME", "plans"),
+		QuarantineCollectionName: getEnvOrDefault("QUARANTINE_COLLECTION_NAME", "quarantined_rates"),
+		APIKeysCollectionName:    getEnvOrDefault("API_KEYS_COLLECTION_NAME", "api_keys"),
+		PlansFile:                getEnvOrDefault("PLANS_FILE", ""),
+		TaxProfilesFile:          getEnvOrDefault("TAX_PROFILES_FILE", ""),
+		MoneyRounding:            moneyRounding,
+		AdminAPIToken:            getEnvOrDefault("ADMIN_API_TOKEN", ""),
 		// --- ASIGNAR LAS NUEVAS VARIABLES ---
-		WhatsAppAPIURL: whatsAppAPIURL,
-		WhatsAppToNumber: whatsAppToNumber,
+		WhatsAppAPIURL:                whatsAppAPIURL,
+		WhatsAppToNumber:              whatsAppToNumber,
+		TelegramBotToken:              getEnvOrDefault("TELEGRAM_BOT_TOKEN", ""),
+		TelegramChatID:                getEnvOrDefault("TELEGRAM_CHAT_ID", ""),
+		SMTPHost:                      getEnvOrDefault("SMTP_HOST", ""),
+		SMTPPort:                      getEnvOrDefault("SMTP_PORT", "587"),
+		SMTPUsername:                  getEnvOrDefault("SMTP_USERNAME", ""),
+		SMTPPassword:                  getEnvOrDefault("SMTP_PASSWORD", ""),
+		SMTPFrom:                      getEnvOrDefault("SMTP_FROM", ""),
+		SMTPTo:                        getEnvList("SMTP_TO"),
+		SlackWebhookURL:               getEnvOrDefault("SLACK_WEBHOOK_URL", ""),
+		AlertWebhookURL:               getEnvOrDefault("ALERT_WEBHOOK_URL", ""),
+		RateSubscriberWhatsAppNumbers: getEnvList("RATE_SUBSCRIBER_WHATSAPP_NUMBERS"),
+		RateSubscriberWebhookURL:      getEnvOrDefault("RATE_SUBSCRIBER_WEBHOOK_URL", ""),
+		LargeMoveThresholdPercent:     largeMoveThresholdPercent,
+		ScrapeMaxAttempts:             scrapeMaxAttempts,
+		ScrapeInitialBackoff:          scrapeInitialBackoff,
+		ScrapeMaxBackoff:              scrapeMaxBackoff,
+		ScrapeRetryInterval:           scrapeRetryInterval,
+		ScrapeMaxFollowUps:            scrapeMaxFollowUps,
+		RateSources:                   rateSources,
+		BCVPageURL:                    getEnvOrDefault("BCV_PAGE_URL", "https://www.bcv.org.ve/"),
+		BCVBulletinURL:                getEnvOrDefault("BCV_XLS_URL", ""),
+		RateJSONURL:                   getEnvOrDefault("RATE_JSON_URL", ""),
+		RateJSONRatesField:            getEnvOrDefault("RATE_JSON_RATES_FIELD", "rates"),
+		RateJSONDateField:             getEnvOrDefault("RATE_JSON_DATE_FIELD", ""),
+		RateSourceTolerancePercent:    rateSourceTolerancePercent,
+		MaxDailyChangePercent:         maxDailyChangePercent,
+		APIKeyRequired:                apiKeyRequired,
+		APIKeyDefaultRatePerMinute:    apiKeyDefaultRatePerMinute,
+		APIKeyDefaultBurst:            apiKeyDefaultBurst,
+		APIKeyDefaultDailyQuota:       apiKeyDefaultDailyQuota,
+		CORSAllowedOrigins:            corsAllowedOrigins,
+		CORSAllowedHeaders:            corsAllowedHeaders,
+		CORSAllowedMethods:            corsAllowedMethods,
+		CORSAllowCredentials:          corsAllowCredentials,
+		CORSMaxAgeSeconds:             corsMaxAgeSeconds,
+		HTTPReadTimeout:               httpReadTimeout,
+		HTTPWriteTimeout:              httpWriteTimeout,
+		HTTPIdleTimeout:               httpIdleTimeout,
+		ShutdownGracePeriod:           shutdownGracePeriod,
+		ReadinessMaxRateAge:           readinessMaxRateAge,
+		LogLevel:                      logLevel,
+		LogFormat:                     logFormat,
+		TracingExporter:               tracingExporter,
+		TracingServiceName:            getEnvOrDefault("TRACING_SERVICE_NAME", "precio-bcv-go"),
+		OTLPEndpoint:                  getEnvOrDefault("OTLP_ENDPOINT", ""),
+		OTLPInsecure:                  otlpInsecure,
+		TracingSampleRatio:            tracingSampleRatio,
 	}, nil // Retorna nil para el error, indicando éxito.
 }
 
 // getRequiredEnv obtiene el valor de una variable de entorno especificada por 'key'.
 // Retorna el valor de la variable o un error si la variable no existe o está vacía.
 func getRequiredEnv(key string) (string, error) {
-	envValue, exists := os.LookupEnv(key) 
+	envValue, exists := os.LookupEnv(key)
 	if !exists || envValue == "" {
 		return "", fmt.Errorf("'%s' no encontrada o vacía. Es una variable de entorno requerida", key)
 	}
 	return envValue, nil
-}
\ No newline at end of file
+}
+
+// getEnvOrDefault obtiene el valor de una variable de entorno opcional.
+// Retorna 'defaultValue' si la variable no existe o está vacía.
+func getEnvOrDefault(key string, defaultValue string) string {
+	envValue, exists := os.LookupEnv(key)
+	if !exists || envValue == "" {
+		return defaultValue
+	}
+	return envValue
+}
+
+// getEnvList obtiene una variable de entorno opcional con valores separados por comas.
+// Retorna nil si la variable no existe o está vacía; se descartan los elementos vacíos.
+func getEnvList(key string) []string {
+	var listValues []string
+	for _, listValue := range strings.Split(getEnvOrDefault(key, ""), ",") {
+		if trimmedValue := strings.TrimSpace(listValue); trimmedValue != "" {
+			listValues = append(listValues, trimmedValue)
+		}
+	}
+	return listValues
+}
+
+// getEnvFloat obtiene una variable de entorno numérica opcional.
+// Retorna 'defaultValue' si la variable no existe o está vacía, o un error si no es un número válido.
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	envValue := getEnvOrDefault(key, "")
+	if envValue == "" {
+		return defaultValue, nil
+	}
+	parsedValue, parseErr := strconv.ParseFloat(envValue, 64)
+	if parseErr != nil {
+		return 0, fmt.Errorf("valor inválido para '%s': '%s' no es un número", key, envValue)
+	}
+	return parsedValue, nil
+}
+
+// getEnvInt obtiene una variable de entorno entera opcional.
+// Retorna 'defaultValue' si la variable no existe o está vacía, o un error si no es un entero válido.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	envValue := getEnvOrDefault(key, "")
+	if envValue == "" {
+		return defaultValue, nil
+	}
+	parsedValue, parseErr := strconv.Atoi(envValue)
+	if parseErr != nil {
+		return 0, fmt.Errorf("valor inválido para '%s': '%s' no es un entero", key, envValue)
+	}
+	return parsedValue, nil
+}
+
+// getEnvBool obtiene una variable de entorno booleana opcional ("true", "false", "1", "0"...).
+// Retorna 'defaultValue' si la variable no existe o está vacía, o un error si no es un booleano válido.
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	envValue := getEnvOrDefault(key, "")
+	if envValue == "" {
+		return defaultValue, nil
+	}
+	parsedValue, parseErr := strconv.ParseBool(envValue)
+	if parseErr != nil {
+		return false, fmt.Errorf("valor inválido para '%s': '%s' no es un booleano (use true o false)", key, envValue)
+	}
+	return parsedValue, nil
+}
+
+// getEnvDuration obtiene una variable de entorno de duración opcional (ej. "15m", "30s").
+// Retorna 'defaultValue' si la variable no existe o está vacía, o un error si no es una duración válida.
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	envValue := getEnvOrDefault(key, "")
+	if envValue == "" {
+		return defaultValue, nil
+	}
+	parsedValue, parseErr := time.ParseDuration(envValue)
+	if parseErr != nil {
+		return 0, fmt.Errorf("valor inválido para '%s': '%s' no es una duración (ej. 30s, 15m)", key, envValue)
+	}
+	return parsedValue, nil
+}
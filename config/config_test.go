@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// setMinimalEnv define las variables mínimas para que LoadConfig funcione con el almacenamiento en memoria.
+func setMinimalEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("PORT", "8080")
+	t.Setenv("STORAGE_BACKEND", "memory")
+}
+
+func TestLoadConfigRejectsWildcardOriginWithCredentials(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("CORS_ALLOWED_ORIGINS", "") // Se usa el origen por defecto, "*".
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	if _, loadErr := LoadConfig(); loadErr == nil || !strings.Contains(loadErr.Error(), "CORS_ALLOW_CREDENTIALS") {
+		t.Fatalf("LoadConfig retornó %v; se esperaba un error por usar '*' con credenciales", loadErr)
+	}
+}
+
+func TestLoadConfigAllowsListedOriginsWithCredentials(t *testing.T) {
+	setMinimalEnv(t)
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	appConfig, loadErr := LoadConfig()
+	if loadErr != nil {
+		t.Fatalf("LoadConfig retornó un error: %v", loadErr)
+	}
+	if !appConfig.CORSAllowCredentials || len(appConfig.CORSAllowedOrigins) != 1 || appConfig.CORSAllowedOrigins[0] != "https://app.example.com" {
+		t.Fatalf("CORS = %v, %v; se esperaba el origen listado con credenciales", appConfig.CORSAllowedOrigins, appConfig.CORSAllowCredentials)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"precio-bcv-go/models"
+	"precio-bcv-go/services"
+)
+
+// writeAPIKeyError traduce los errores de APIKeyService al código de estado HTTP correspondiente.
+func writeAPIKeyError(httpResponseWriter http.ResponseWriter, apiKeyErr error) {
+	switch {
+	case errors.Is(apiKeyErr, services.ErrAPIKeyNotFound):
+		http.Error(httpResponseWriter, "API key not found", http.StatusNotFound)
+	case errors.Is(apiKeyErr, services.ErrInvalidAPIKeyRequest):
+		http.Error(httpResponseWriter, apiKeyErr.Error(), http.StatusBadRequest)
+	default:
+		http.Error(httpResponseWriter, "Could not process API key", http.StatusInternalServerError)
+	}
+}
+
+// HandleAdminListAPIKeys maneja "GET /admin/api-keys", retornando las claves emitidas (sin la clave en claro).
+func (apiHandler *APIHandlers) HandleAdminListAPIKeys(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	apiKeys, listErr := apiHandler.APIKeyService.ListAPIKeys(httpRequest.Context())
+	if listErr != nil {
+		writeAPIKeyError(httpResponseWriter, listErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, apiKeys)
+}
+
+// HandleAdminIssueAPIKey maneja "POST /admin/api-keys", emitiendo una clave nueva.
+// La respuesta incluye la clave en claro, que no se vuelve a mostrar.
+func (apiHandler *APIHandlers) HandleAdminIssueAPIKey(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	var keyRequest models.APIKeyRequest
+	if decodeErr := json.NewDecoder(httpRequest.Body).Decode(&keyRequest); decodeErr != nil {
+		http.Error(httpResponseWriter, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	issuedKey, issueErr := apiHandler.APIKeyService.IssueAPIKey(httpRequest.Context(), keyRequest)
+	if issueErr != nil {
+		writeAPIKeyError(httpResponseWriter, issueErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusCreated, issuedKey)
+}
+
+// HandleAdminRevokeAPIKey maneja "DELETE /admin/api-keys/{id}", revocando la clave indicada.
+func (apiHandler *APIHandlers) HandleAdminRevokeAPIKey(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	revokedKey, revokeErr := apiHandler.APIKeyService.RevokeAPIKey(httpRequest.Context(), httpRequest.PathValue("id"))
+	if revokeErr != nil {
+		writeAPIKeyError(httpResponseWriter, revokeErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, revokedKey)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"precio-bcv-go/models"
+	"precio-bcv-go/services"
+)
+
+// writeJSON responde con el código de estado indicado y el cuerpo serializado como JSON.
+func writeJSON(httpResponseWriter http.ResponseWriter, statusCode int, responseBody any) {
+	httpResponseWriter.Header().Set("Content-Type", "application/json")
+	httpResponseWriter.WriteHeader(statusCode)
+	json.NewEncoder(httpResponseWriter).Encode(responseBody)
+}
+
+// writePlanError traduce los errores de PlanService al código de estado HTTP correspondiente.
+func writePlanError(httpResponseWriter http.ResponseWriter, planErr error) {
+	switch {
+	case errors.Is(planErr, services.ErrPlanNotFound):
+		http.Error(httpResponseWriter, "Plan not found", http.StatusNotFound)
+	case errors.Is(planErr, services.ErrPlanExists):
+		http.Error(httpResponseWriter, "Plan already exists", http.StatusConflict)
+	case errors.Is(planErr, services.ErrInvalidPlan):
+		http.Error(httpResponseWriter, planErr.Error(), http.StatusBadRequest)
+	default:
+		http.Error(httpResponseWriter, "Could not process plan", http.StatusInternalServerError)
+	}
+}
+
+// HandleAdminListPlans maneja "GET /admin/plans", retornando todo el catálogo, incluidos los planes inactivos.
+func (apiHandler *APIHandlers) HandleAdminListPlans(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	allPlans, listErr := apiHandler.PlanCatalogService.ListPlans(httpRequest.Context())
+	if listErr != nil {
+		writePlanError(httpResponseWriter, listErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, allPlans)
+}
+
+// HandleAdminGetPlan maneja "GET /admin/plans/{id}".
+func (apiHandler *APIHandlers) HandleAdminGetPlan(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	plan, getErr := apiHandler.PlanCatalogService.GetPlan(httpRequest.Context(), httpRequest.PathValue("id"))
+	if getErr != nil {
+		writePlanError(httpResponseWriter, getErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, plan)
+}
+
+// HandleAdminCreatePlan maneja "POST /admin/plans", agregando un plan al catálogo.
+func (apiHandler *APIHandlers) HandleAdminCreatePlan(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	var newPlan models.Plan
+	if decodeErr := json.NewDecoder(httpRequest.Body).Decode(&newPlan); decodeErr != nil {
+		http.Error(httpResponseWriter, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	createdPlan, createErr := apiHandler.PlanCatalogService.CreatePlan(httpRequest.Context(), newPlan)
+	if createErr != nil {
+		writePlanError(httpResponseWriter, createErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusCreated, createdPlan)
+}
+
+// HandleAdminUpdatePlan maneja "PUT /admin/plans/{id}", reemplazando un plan existente.
+// El ID de la ruta tiene prioridad sobre el del cuerpo.
+func (apiHandler *APIHandlers) HandleAdminUpdatePlan(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	var updatedPlan models.Plan
+	if decodeErr := json.NewDecoder(httpRequest.Body).Decode(&updatedPlan); decodeErr != nil {
+		http.Error(httpResponseWriter, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	updatedPlan.ID = httpRequest.PathValue("id")
+
+	storedPlan, updateErr := apiHandler.PlanCatalogService.UpdatePlan(httpRequest.Context(), updatedPlan)
+	if updateErr != nil {
+		writePlanError(httpResponseWriter, updateErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, storedPlan)
+}
+
+// HandleAdminDeletePlan maneja "DELETE /admin/plans/{id}".
+func (apiHandler *APIHandlers) HandleAdminDeletePlan(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	if deleteErr := apiHandler.PlanCatalogService.DeletePlan(httpRequest.Context(), httpRequest.PathValue("id")); deleteErr != nil {
+		writePlanError(httpResponseWriter, deleteErr)
+		return
+	}
+	httpResponseWriter.WriteHeader(http.StatusNoContent)
+}
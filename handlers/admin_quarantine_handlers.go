@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"precio-bcv-go/models"
+	"precio-bcv-go/services"
+)
+
+// writeQuarantineError traduce los errores de la revisión de cuarentena al código de estado HTTP correspondiente.
+func writeQuarantineError(httpResponseWriter http.ResponseWriter, quarantineErr error) {
+	switch {
+	case errors.Is(quarantineErr, services.ErrQuarantinedRateNotFound):
+		http.Error(httpResponseWriter, "Quarantined rate not found", http.StatusNotFound)
+	case errors.Is(quarantineErr, services.ErrQuarantinedRateReviewed):
+		http.Error(httpResponseWriter, "Quarantined rate already reviewed", http.StatusConflict)
+	default:
+		http.Error(httpResponseWriter, "Could not process quarantined rate", http.StatusInternalServerError)
+	}
+}
+
+// HandleAdminListQuarantine maneja "GET /admin/quarantine", retornando las tasas retenidas por la verificación
+// de variación diaria. El parámetro opcional "status" filtra por estado (pending, approved o rejected).
+func (apiHandler *APIHandlers) HandleAdminListQuarantine(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	status := httpRequest.URL.Query().Get("status")
+	switch status {
+	case "", services.QuarantinePending, services.QuarantineApproved, services.QuarantineRejected:
+	default:
+		http.Error(httpResponseWriter, "Invalid 'status' parameter (use pending, approved or rejected)", http.StatusBadRequest)
+		return
+	}
+
+	quarantinedRates, listErr := apiHandler.BCVValueService.ListQuarantinedRates(httpRequest.Context(), status)
+	if listErr != nil {
+		writeQuarantineError(httpResponseWriter, listErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, quarantinedRates)
+}
+
+// HandleAdminApproveQuarantine maneja "POST /admin/quarantine/{id}/approve", publicando la tasa retenida.
+func (apiHandler *APIHandlers) HandleAdminApproveQuarantine(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	apiHandler.reviewQuarantine(httpResponseWriter, httpRequest, apiHandler.BCVValueService.ApproveQuarantinedRate)
+}
+
+// HandleAdminRejectQuarantine maneja "POST /admin/quarantine/{id}/reject", descartando la tasa retenida.
+func (apiHandler *APIHandlers) HandleAdminRejectQuarantine(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	apiHandler.reviewQuarantine(httpResponseWriter, httpRequest, apiHandler.BCVValueService.RejectQuarantinedRate)
+}
+
+// reviewQuarantine aplica la revisión indicada a la tasa en cuarentena de la ruta y responde con su estado final.
+func (apiHandler *APIHandlers) reviewQuarantine(httpResponseWriter http.ResponseWriter, httpRequest *http.Request, reviewFunc func(context.Context, string) (models.QuarantinedRate, error)) {
+	reviewedRate, reviewErr := reviewFunc(httpRequest.Context(), httpRequest.PathValue("id"))
+	if reviewErr != nil {
+		writeQuarantineError(httpResponseWriter, reviewErr)
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, reviewedRate)
+}
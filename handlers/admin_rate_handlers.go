@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"precio-bcv-go/services"
+
+	"github.com/shopspring/decimal"
+)
+
+// manualRateRequest es el cuerpo de "PUT /admin/rates/{date}". Las tasas pueden enviarse como números
+// o como texto (ej. "36.12345678"); en ambos casos se guardan exactamente como se enviaron.
+// "author" es informativo: lo declara quien hace la petición y no se verifica. El registro guarda además
+// qué token de administración se usó, que no distingue entre personas que comparten el token.
+type manualRateRequest struct {
+	Rates  map[string]decimal.Decimal `json:"rates"`
+	Author string                     `json:"author"`
+	Note   string                     `json:"note"`
+}
+
+// writeRateError traduce los errores de la carga manual de tasas al código de estado HTTP correspondiente.
+func writeRateError(httpResponseWriter http.ResponseWriter, rateErr error) {
+	switch {
+	case errors.Is(rateErr, services.ErrRateNotFound):
+		http.Error(httpResponseWriter, "No rate stored for that date", http.StatusNotFound)
+	case errors.Is(rateErr, services.ErrInvalidManualRate):
+		http.Error(httpResponseWriter, rateErr.Error(), http.StatusBadRequest)
+	default:
+		http.Error(httpResponseWriter, "Could not process rate", http.StatusInternalServerError)
+	}
+}
+
+// HandleAdminSetRate maneja "PUT /admin/rates/{date}", registrando a mano las tasas con esa fecha valor (YYYY-MM-DD).
+// Si ya había tasas para ese día se reemplazan (200); si no, se crean (201). El cambio se aplica de inmediato.
+func (apiHandler *APIHandlers) HandleAdminSetRate(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	effectiveDay, dateParseErr := time.ParseInLocation("2006-01-02", httpRequest.PathValue("date"), time.Local)
+	if dateParseErr != nil {
+		http.Error(httpResponseWriter, "Invalid date (use YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	var rateRequest manualRateRequest
+	if decodeErr := json.NewDecoder(httpRequest.Body).Decode(&rateRequest); decodeErr != nil {
+		http.Error(httpResponseWriter, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	storedRecord, replacedExisting, setErr := apiHandler.BCVValueService.SetManualRate(httpRequest.Context(), effectiveDay, rateRequest.Rates, rateRequest.Author, AdminCredentialFromContext(httpRequest.Context()), rateRequest.Note)
+	if setErr != nil {
+		writeRateError(httpResponseWriter, setErr)
+		return
+	}
+	statusCode := http.StatusCreated
+	if replacedExisting {
+		statusCode = http.StatusOK
+	}
+	writeJSON(httpResponseWriter, statusCode, storedRecord)
+}
+
+// HandleAdminDeleteRate maneja "DELETE /admin/rates/{date}", eliminando las tasas con esa fecha valor.
+func (apiHandler *APIHandlers) HandleAdminDeleteRate(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	effectiveDay, dateParseErr := time.ParseInLocation("2006-01-02", httpRequest.PathValue("date"), time.Local)
+	if dateParseErr != nil {
+		http.Error(httpResponseWriter, "Invalid date (use YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	if deleteErr := apiHandler.BCVValueService.DeleteRate(httpRequest.Context(), effectiveDay); deleteErr != nil {
+		writeRateError(httpResponseWriter, deleteErr)
+		return
+	}
+	httpResponseWriter.WriteHeader(http.StatusNoContent)
+}
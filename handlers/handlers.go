@@ -2,68 +2,348 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"precio-bcv-go/models"
 	"precio-bcv-go/services"
 	"precio-bcv-go/utils"
+
+	"github.com/shopspring/decimal"
 )
 
 // APIHandlers contiene las dependencias de servicio necesarias para manejar las peticiones HTTP de la API.
 type APIHandlers struct {
-	BCVValueService *services.BCVService
+	BCVValueService    *services.BCVService
+	PlanCatalogService *services.PlanService
+	TaxCalculator      *services.TaxEngine
+	APIKeyService      *services.APIKeyService
 }
 
 // NewAPIHandlers es el constructor para crear una nueva instancia de APIHandlers.
-func NewAPIHandlers(bcvServiceInstance *services.BCVService) *APIHandlers {
+func NewAPIHandlers(bcvServiceInstance *services.BCVService, planServiceInstance *services.PlanService, taxEngineInstance *services.TaxEngine, apiKeyServiceInstance *services.APIKeyService) *APIHandlers {
 	return &APIHandlers{
-		BCVValueService: bcvServiceInstance,
+		BCVValueService:    bcvServiceInstance,
+		PlanCatalogService: planServiceInstance,
+		TaxCalculator:      taxEngineInstance,
+		APIKeyService:      apiKeyServiceInstance,
+	}
+}
+
+// currencyFromRequest obtiene el parámetro "currency" de la petición, usando USD por defecto.
+// Retorna false si la moneda solicitada no es publicada por el BCV.
+func currencyFromRequest(httpRequest *http.Request) (string, bool) {
+	requestedCurrency := strings.ToUpper(strings.TrimSpace(httpRequest.URL.Query().Get("currency")))
+	if requestedCurrency == "" {
+		return services.DefaultCurrency, true
 	}
+	_, isSupported := services.SupportedCurrencies[requestedCurrency]
+	return requestedCurrency, isSupported
 }
 
-// HandleRequest maneja la ruta raíz ("/") de la API, retornando el valor actual del BCV.
+// HandleRequest maneja la ruta raíz ("/") de la API, retornando el valor actual del BCV
+// para la moneda indicada en el parámetro "currency" (USD por defecto), con la fecha valor, el origen
+// y la vigencia de la tasa. Si aún no hay tasa para esa moneda responde 503 en lugar de un valor 0.
 func (apiHandler *APIHandlers) HandleRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
-	currentBCVValue := apiHandler.BCVValueService.GetBCV()
-	
+	currency, isSupported := currencyFromRequest(httpRequest)
+	if !isSupported {
+		http.Error(httpResponseWriter, "Invalid currency parameter", http.StatusBadRequest)
+		return
+	}
+
+	// El valor y sus datos salen del mismo snapshot, aunque una actualización publique tasas nuevas entretanto.
+	rateSnapshot := apiHandler.BCVValueService.CurrentRates()
+	currentBCVValue := rateSnapshot.Rate(currency)
+	if !currentBCVValue.IsPositive() {
+		http.Error(httpResponseWriter, "Rate not available: no BCV rate has been obtained for "+currency+" yet", http.StatusServiceUnavailable)
+		return
+	}
+
 	jsonResponse := models.Response{
-		BCV: currentBCVValue,
+		BCV:          currentBCVValue,
+		Currency:     currency,
+		RateMetadata: rateSnapshot.Metadata(),
 	}
 
 	httpResponseWriter.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(httpResponseWriter).Encode(jsonResponse)
 }
 
-// HandlePlansRequest maneja la ruta "/plans" de la API, retornando precios de planes calculados.
+// HandlePlansRequest maneja la ruta "/plans" de la API, retornando el precio en bolívares
+// de cada plan activo del catálogo, junto con los datos de la tasa usada. Responde 503 si aún no hay tasa.
+// Un plan cuyo perfil de impuestos no puede calcularse (se quitó de TAX_PROFILES_FILE, o solo tiene versiones
+// futuras) se registra en el log y se omite, sin afectar a los demás.
 func (apiHandler *APIHandlers) HandlePlansRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
-	currentBCVValue := apiHandler.BCVValueService.GetBCV()
-	const taxRate = 1.08 // Tasa de impuesto del 8%
+	activePlans, listErr := apiHandler.PlanCatalogService.ListActivePlans(httpRequest.Context())
+	if listErr != nil {
+		http.Error(httpResponseWriter, "Could not retrieve plans", http.StatusInternalServerError)
+		return
+	}
 
-	plansResponse := models.PlansResponse{
-		Price20: utils.FormatFloat((currentBCVValue * 20) * taxRate),
-		Price25: utils.FormatFloat((currentBCVValue * 25) * taxRate),
-		Price30: utils.FormatFloat((currentBCVValue * 30) * taxRate),
+	rateSnapshot := apiHandler.BCVValueService.CurrentRates()
+	currentBCVValue := rateSnapshot.Value
+	if !currentBCVValue.IsPositive() {
+		http.Error(httpResponseWriter, "Rate not available: no BCV rate has been obtained yet", http.StatusServiceUnavailable)
+		return
+	}
+	calculationDate := time.Now()
+
+	plansResponse := models.PlansResponse{Plans: make([]models.PlanPrice, 0, len(activePlans)), RateMetadata: rateSnapshot.Metadata()}
+	for _, plan := range activePlans {
+		// Cada plan usa su propio perfil de impuestos. El producto es exacto; solo se redondea dentro de Calculate.
+		planBreakdown, taxErr := apiHandler.TaxCalculator.Calculate(plan.TaxProfile, currentBCVValue.Mul(plan.PriceUSD), calculationDate)
+		if taxErr != nil {
+			slog.WarnContext(httpRequest.Context(), "Plan omitido: su perfil de impuestos no puede calcularse", "plan_id", plan.ID, "tax_profile", plan.TaxProfile, "error", taxErr)
+			continue
+		}
+		plansResponse.Plans = append(plansResponse.Plans, models.PlanPrice{
+			ID:         plan.ID,
+			Name:       plan.Name,
+			PriceUSD:   plan.PriceUSD,
+			TaxProfile: plan.TaxProfile,
+			Price:      planBreakdown.Total,
+			Breakdown:  planBreakdown,
+		})
 	}
 
 	httpResponseWriter.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(httpResponseWriter).Encode(plansResponse)
 }
 
-// HandleConvertRequest maneja la ruta "/convert" de la API, convirtiendo un monto dado.
+// HandleConvertRequest maneja la ruta "/convert" de la API, convirtiendo "amount" desde la moneda "from"
+// (USD por defecto; "currency" se acepta como alias) hacia la moneda "to" (VES por defecto).
+// Se admite cualquier par de monedas guardadas, incluido el bolívar; los cruces entre divisas se hacen vía VES.
+// Los impuestos solo se aplican si se pide un perfil explícito con "tax_profile". La conversión se calcula
+// con decimales exactos y se redondea a céntimos una sola vez, con la regla de MONEY_ROUNDING.
 func (apiHandler *APIHandlers) HandleConvertRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
-	amountToConvert, parseErr := strconv.ParseFloat(httpRequest.URL.Query().Get("amount"), 64)
-	if parseErr != nil {
+	queryParams := httpRequest.URL.Query()
+
+	amountToConvert, amountValid := parseAmountParam(queryParams.Get("amount"))
+	if !amountValid {
 		http.Error(httpResponseWriter, "Invalid amount parameter", http.StatusBadRequest)
 		return
 	}
 
-	currentBCVValue := apiHandler.BCVValueService.GetBCV()
-	const taxRate = 1.08 // Tasa de impuesto del 8%
+	fromParam := queryParams.Get("from")
+	if fromParam == "" {
+		fromParam = queryParams.Get("currency")
+	}
+	fromCurrency, fromValid := parseConversionCurrency(fromParam, services.DefaultCurrency)
+	toCurrency, toValid := parseConversionCurrency(queryParams.Get("to"), services.BolivarCurrency)
+	if !fromValid || !toValid {
+		http.Error(httpResponseWriter, "Invalid from/to currency parameter", http.StatusBadRequest)
+		return
+	}
+
+	rateSnapshot := apiHandler.BCVValueService.CurrentRates()
+	conversionRate, rateAvailable := rateSnapshot.ConversionRate(fromCurrency, toCurrency)
+	if !rateAvailable {
+		http.Error(httpResponseWriter, "Rate not available for the requested currencies", http.StatusServiceUnavailable)
+		return
+	}
+	convertedAmount, _ := rateSnapshot.Convert(amountToConvert, fromCurrency, toCurrency) // Mismas tasas que ConversionRate.
 
 	conversionResult := models.ConversionResponse{
-		Conversion: utils.FormatFloat((amountToConvert * currentBCVValue) * taxRate),
+		From:   fromCurrency,
+		To:     toCurrency,
+		Amount: amountToConvert,
+		// Las tasas del BCV tienen 8 decimales; solo los cruces entre divisas (ej. EUR a USD) se redondean aquí.
+		Rate:         utils.Round(conversionRate, utils.RatePlaces, utils.RoundHalfEven),
+		Conversion:   apiHandler.TaxCalculator.RoundAmount(convertedAmount),
+		RateMetadata: rateSnapshot.Metadata(),
+	}
+
+	if taxProfile := queryParams.Get("tax_profile"); taxProfile != "" {
+		conversionBreakdown, taxErr := apiHandler.TaxCalculator.Calculate(taxProfile, convertedAmount, time.Now())
+		if taxErr != nil {
+			http.Error(httpResponseWriter, "Invalid tax_profile parameter", http.StatusBadRequest)
+			return
+		}
+		conversionResult.Conversion = conversionBreakdown.Total
+		conversionResult.Breakdown = &conversionBreakdown
 	}
 
 	httpResponseWriter.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(httpResponseWriter).Encode(conversionResult)
-}
\ No newline at end of file
+}
+
+// Límites del parámetro "amount" de "/convert". El costo de operar con un decimal crece con su exponente,
+// por lo que un monto como "1e2000000" se rechaza antes de calcular nada.
+const (
+	maxAmountLength   = 32
+	maxAmountExponent = 18 // Exponente máximo, en valor absoluto, del monto en notación científica (ej. "1e18" o "1e-18").
+)
+
+// parseAmountParam convierte el parámetro "amount" a decimal. Retorna false si está vacío, no es un número,
+// no es mayor que 0, supera maxAmountLength caracteres o su exponente está fuera de ±maxAmountExponent.
+func parseAmountParam(paramValue string) (decimal.Decimal, bool) {
+	amountText := strings.TrimSpace(paramValue)
+	if amountText == "" || len(amountText) > maxAmountLength {
+		return decimal.Zero, false
+	}
+	parsedAmount, parseErr := decimal.NewFromString(amountText)
+	if parseErr != nil || !parsedAmount.IsPositive() {
+		return decimal.Zero, false
+	}
+	// El valor es coeficiente × 10^exponente; el coeficiente ya está acotado por la longitud del texto.
+	if amountExponent := parsedAmount.Exponent(); amountExponent > maxAmountExponent || amountExponent < -maxAmountExponent {
+		return decimal.Zero, false
+	}
+	return parsedAmount, true
+}
+
+// parseConversionCurrency normaliza una moneda de conversión, usando 'defaultCurrency' si está vacía.
+// Acepta las monedas publicadas por el BCV y el bolívar (VES).
+func parseConversionCurrency(paramValue string, defaultCurrency string) (string, bool) {
+	requestedCurrency := strings.ToUpper(strings.TrimSpace(paramValue))
+	if requestedCurrency == "" {
+		return defaultCurrency, true
+	}
+	if requestedCurrency == services.BolivarCurrency {
+		return requestedCurrency, true
+	}
+	_, isSupported := services.SupportedCurrencies[requestedCurrency]
+	return requestedCurrency, isSupported
+}
+
+// HandleRateOnDateRequest maneja la ruta "/rate" de la API, retornando la tasa legalmente vigente
+// en la fecha "date" (YYYY-MM-DD) según la "Fecha Valor" del BCV. Fines de semana y feriados
+// resuelven al último valor publicado. Acepta el parámetro "currency" (USD por defecto).
+func (apiHandler *APIHandlers) HandleRateOnDateRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	requestedDate, dateParseErr := time.ParseInLocation("2006-01-02", httpRequest.URL.Query().Get("date"), time.Local)
+	if dateParseErr != nil {
+		http.Error(httpResponseWriter, "Invalid date parameter (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	currency, isSupported := currencyFromRequest(httpRequest)
+	if !isSupported {
+		http.Error(httpResponseWriter, "Invalid currency parameter", http.StatusBadRequest)
+		return
+	}
+
+	// Una tasa con fecha valor en cualquier momento del día solicitado está vigente ese día.
+	endOfRequestedDate := requestedDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	effectiveRecord, lookupErr := apiHandler.BCVValueService.GetRateInEffectOn(httpRequest.Context(), endOfRequestedDate)
+	if lookupErr != nil {
+		http.Error(httpResponseWriter, "Could not retrieve rate for date", http.StatusInternalServerError)
+		return
+	}
+	if effectiveRecord == nil || !effectiveRecord.RateFor(currency).IsPositive() {
+		http.Error(httpResponseWriter, "No rate in effect for the requested date", http.StatusNotFound)
+		return
+	}
+
+	effectiveDate := effectiveRecord.EffectiveDate
+	if effectiveDate.IsZero() {
+		effectiveDate = effectiveRecord.Timestamp // Documentos anteriores a la captura de la fecha valor.
+	}
+
+	rateResponse := models.RateOnDateResponse{
+		Date:          requestedDate.Format("2006-01-02"),
+		EffectiveDate: effectiveDate.In(time.Local).Format("2006-01-02"),
+		Currency:      currency,
+		BCV:           effectiveRecord.RateFor(currency),
+	}
+
+	httpResponseWriter.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(httpResponseWriter).Encode(rateResponse)
+}
+
+// Valores de paginación para la ruta "/history".
+const (
+	defaultHistoryPageSize = 30
+	maxHistoryPageSize     = 366
+)
+
+// HandleHistoryRequest maneja la ruta "/history" de la API, retornando las tasas guardadas
+// entre las fechas "from" y "to" (formato YYYY-MM-DD, ambas inclusive), paginadas con "page" y "page_size".
+// Las fechas filtran por el día en que se obtuvo cada registro (su "timestamp"), no por su "Fecha Valor":
+// una tasa publicada el viernes con fecha valor del lunes aparece en el viernes. La tasa vigente en una
+// fecha valor se consulta en "/rate?date=". Los datos de auditoría de las cargas manuales no se incluyen;
+// se consultan en "/admin/history".
+func (apiHandler *APIHandlers) HandleHistoryRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	historyPage, historyRecords, historyOK := apiHandler.rateHistory(httpResponseWriter, httpRequest)
+	if !historyOK {
+		return
+	}
+
+	publicRates := make([]models.HistoryRate, 0, len(historyRecords))
+	for _, historyRecord := range historyRecords {
+		publicRates = append(publicRates, historyRecord.PublicView())
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, models.HistoryResponse{HistoryPage: historyPage, Rates: publicRates})
+}
+
+// HandleAdminHistoryRequest maneja "GET /admin/history". Acepta los mismos parámetros que "/history",
+// pero retorna los registros completos, incluidos el autor, la credencial y la nota de las cargas manuales.
+func (apiHandler *APIHandlers) HandleAdminHistoryRequest(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	historyPage, historyRecords, historyOK := apiHandler.rateHistory(httpResponseWriter, httpRequest)
+	if !historyOK {
+		return
+	}
+	writeJSON(httpResponseWriter, http.StatusOK, models.AdminHistoryResponse{HistoryPage: historyPage, Rates: historyRecords})
+}
+
+// rateHistory valida los parámetros de una consulta del historial y retorna la página solicitada.
+// Si los parámetros no son válidos o la consulta falla, ya respondió con el error y retorna false.
+func (apiHandler *APIHandlers) rateHistory(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) (models.HistoryPage, []models.BCVRate, bool) {
+	queryParams := httpRequest.URL.Query()
+
+	fromDate, fromParseErr := time.ParseInLocation("2006-01-02", queryParams.Get("from"), time.Local)
+	if fromParseErr != nil {
+		http.Error(httpResponseWriter, "Invalid from parameter (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return models.HistoryPage{}, nil, false
+	}
+	toDate, toParseErr := time.ParseInLocation("2006-01-02", queryParams.Get("to"), time.Local)
+	if toParseErr != nil {
+		http.Error(httpResponseWriter, "Invalid to parameter (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return models.HistoryPage{}, nil, false
+	}
+	if toDate.Before(fromDate) {
+		http.Error(httpResponseWriter, "The to parameter must not be before from", http.StatusBadRequest)
+		return models.HistoryPage{}, nil, false
+	}
+
+	pageNumber, pageValid := positiveIntParam(queryParams.Get("page"), 1)
+	pageSize, pageSizeValid := positiveIntParam(queryParams.Get("page_size"), defaultHistoryPageSize)
+	// El límite de "page" evita que (page-1)*page_size desborde al calcular el salto en el almacenamiento.
+	if !pageValid || !pageSizeValid || pageSize > maxHistoryPageSize || pageNumber > math.MaxInt/pageSize {
+		http.Error(httpResponseWriter, "Invalid pagination parameters", http.StatusBadRequest)
+		return models.HistoryPage{}, nil, false
+	}
+
+	// "to" incluye el día completo.
+	endOfToDate := toDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	historyRecords, totalRecords, historyErr := apiHandler.BCVValueService.GetRateHistory(httpRequest.Context(), fromDate, endOfToDate, pageNumber, pageSize)
+	if historyErr != nil {
+		http.Error(httpResponseWriter, "Could not retrieve rate history", http.StatusInternalServerError)
+		return models.HistoryPage{}, nil, false
+	}
+
+	historyPage := models.HistoryPage{
+		From:     fromDate.Format("2006-01-02"),
+		To:       toDate.Format("2006-01-02"),
+		Page:     pageNumber,
+		PageSize: pageSize,
+		Total:    totalRecords,
+	}
+	return historyPage, historyRecords, true
+}
+
+// positiveIntParam convierte un parámetro de la petición a entero positivo.
+// Retorna el valor por defecto si el parámetro está vacío, y false si no es un entero positivo.
+func positiveIntParam(paramValue string, defaultValue int) (int, bool) {
+	if paramValue == "" {
+		return defaultValue, true
+	}
+	parsedValue, parseErr := strconv.Atoi(paramValue)
+	if parseErr != nil || parsedValue < 1 {
+		return 0, false
+	}
+	return parsedValue, true
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"precio-bcv-go/models"
+	"precio-bcv-go/services"
+)
+
+// HealthHandlers atiende las sondas de liveness y readiness de Kubernetes.
+type HealthHandlers struct {
+	BCVValueService *services.BCVService
+}
+
+// NewHealthHandlers es el constructor para crear una nueva instancia de HealthHandlers.
+func NewHealthHandlers(bcvServiceInstance *services.BCVService) *HealthHandlers {
+	return &HealthHandlers{
+		BCVValueService: bcvServiceInstance,
+	}
+}
+
+// HandleLiveness maneja "GET /healthz". Solo indica que el proceso responde; no revisa dependencias,
+// para que Kubernetes no reinicie el pod por una caída de MongoDB o del sitio del BCV.
+func (healthHandler *HealthHandlers) HandleLiveness(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	writeJSON(httpResponseWriter, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// HandleReadiness maneja "GET /readyz". Responde 503 si el almacenamiento no responde, si no hay tasa
+// del dólar o si está desactualizada (con la misma regla que el campo "stale" de las demás respuestas,
+// ver services.RateSnapshot.StaleReason), con el estado de cada dependencia.
+func (healthHandler *HealthHandlers) HandleReadiness(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+	readiness := models.ReadinessResponse{
+		Status:  "ok",
+		Storage: models.DependencyStatus{Status: "ok"},
+		Rate:    models.RateStatus{DependencyStatus: models.DependencyStatus{Status: "ok"}},
+	}
+
+	if pingErr := healthHandler.BCVValueService.PingStorage(httpRequest.Context()); pingErr != nil {
+		// El error del driver puede incluir hosts o la URI de conexión; solo se registra en el log.
+		slog.WarnContext(httpRequest.Context(), "El almacenamiento no responde", "error", pingErr)
+		readiness.Storage = models.DependencyStatus{Status: "error", Error: "storage unreachable"}
+	}
+
+	rateSnapshot := healthHandler.BCVValueService.CurrentRates()
+	currentRate, rateDate := rateSnapshot.Value, rateSnapshot.EffectiveDate
+	lastSuccessfulScrape := healthHandler.BCVValueService.LastSuccessfulScrape()
+	readiness.Rate.BCV = currentRate
+	if !lastSuccessfulScrape.IsZero() {
+		readiness.LastSuccessfulScrape = &lastSuccessfulScrape
+	}
+	if !rateDate.IsZero() {
+		readiness.Rate.RateDate = &rateDate
+		if rateAge := time.Since(rateDate); rateAge > 0 {
+			readiness.Rate.Age = rateAge.Round(time.Second).String()
+		}
+	}
+	if !currentRate.IsPositive() {
+		readiness.Rate.Status, readiness.Rate.Error = "error", "no rate available"
+	} else if staleReason := rateSnapshot.StaleReason(); staleReason != "" {
+		readiness.Rate.Status, readiness.Rate.Error = "error", staleReason
+	}
+
+	statusCode := http.StatusOK
+	if readiness.Storage.Status != "ok" || readiness.Rate.Status != "ok" {
+		readiness.Status = "unavailable"
+		statusCode = http.StatusServiceUnavailable
+	}
+	writeJSON(httpResponseWriter, statusCode, readiness)
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"precio-bcv-go/logging"
+	"precio-bcv-go/metrics"
+	"precio-bcv-go/models"
+	"precio-bcv-go/services"
+	"precio-bcv-go/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// adminCredentialContextKey es la clave con la que el middleware de administración guarda la credencial usada en el contexto.
+type adminCredentialContextKey struct{}
+
+// AdminCredentialFromContext retorna la identidad de la credencial de administración con la que se autenticó
+// la petición (ej. "admin-token:3f2a9c0b1d4e"), o "" si no pasó por NewAdminAuth.
+func AdminCredentialFromContext(requestContext context.Context) string {
+	credential, _ := requestContext.Value(adminCredentialContextKey{}).(string)
+	return credential
+}
+
+// adminTokenCredential identifica al token de administración por el prefijo de su hash SHA-256, para registrar
+// con qué token se autenticó un cambio sin guardar el token. Cambia al rotar el token. Como el token es
+// compartido, no identifica a la persona que hizo el cambio.
+func adminTokenCredential(adminToken string) string {
+	tokenHash := sha256.Sum256([]byte(adminToken))
+	return "admin-token:" + hex.EncodeToString(tokenHash[:6])
+}
+
+// NewAdminAuth retorna un middleware que exige el encabezado "Authorization: Bearer <token>"
+// con el token de administración configurado. Si el token está vacío, las rutas protegidas
+// responden 503 para que la API de administración quede deshabilitada por defecto.
+// La identidad de la credencial queda en el contexto de la petición (ver AdminCredentialFromContext).
+func NewAdminAuth(adminToken string) func(http.HandlerFunc) http.HandlerFunc {
+	credential := adminTokenCredential(adminToken)
+	return func(nextHandler http.HandlerFunc) http.HandlerFunc {
+		return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+			if adminToken == "" {
+				http.Error(httpResponseWriter, "Admin API disabled", http.StatusServiceUnavailable)
+				return
+			}
+
+			providedToken, hasBearer := strings.CutPrefix(httpRequest.Header.Get("Authorization"), "Bearer ")
+			if !hasBearer || subtle.ConstantTimeCompare([]byte(providedToken), []byte(adminToken)) != 1 {
+				httpResponseWriter.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(httpResponseWriter, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			nextHandler(httpResponseWriter, httpRequest.WithContext(context.WithValue(httpRequest.Context(), adminCredentialContextKey{}, credential)))
+		}
+	}
+}
+
+// apiKeyContextKey es la clave con la que el middleware de claves de API guarda la clave del cliente en el contexto.
+type apiKeyContextKey struct{}
+
+// APIKeyFromContext retorna la clave de API con la que se autenticó la petición, o false si no envió ninguna.
+func APIKeyFromContext(requestContext context.Context) (models.APIKey, bool) {
+	apiKey, hasKey := requestContext.Value(apiKeyContextKey{}).(models.APIKey)
+	return apiKey, hasKey
+}
+
+// NewAPIKeyAuth retorna un middleware que identifica al cliente por su clave de API, enviada en el
+// encabezado "X-API-Key", y le aplica su límite por minuto y su cuota diaria. La clave no se acepta en la
+// URL, ya que quedaría en los logs de acceso, en los proxies y en el historial del navegador.
+// Si 'keyRequired' es false, las peticiones sin clave se atienden igual; las que envían una clave inválida no.
+func NewAPIKeyAuth(apiKeyService *services.APIKeyService, keyRequired bool) func(http.HandlerFunc) http.HandlerFunc {
+	return func(nextHandler http.HandlerFunc) http.HandlerFunc {
+		return func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+			providedKey := httpRequest.Header.Get("X-API-Key")
+			if providedKey == "" {
+				if keyRequired {
+					http.Error(httpResponseWriter, "API key required", http.StatusUnauthorized)
+					return
+				}
+				nextHandler(httpResponseWriter, httpRequest)
+				return
+			}
+
+			apiKey, keyUsage, authorizeErr := apiKeyService.Authorize(httpRequest.Context(), providedKey)
+			var rateLimitErr *services.RateLimitError
+			switch {
+			case authorizeErr == nil:
+			case errors.Is(authorizeErr, services.ErrInvalidAPIKey):
+				http.Error(httpResponseWriter, "Invalid API key", http.StatusUnauthorized)
+				return
+			case errors.As(authorizeErr, &rateLimitErr):
+				slog.WarnContext(httpRequest.Context(), "Clave de API limitada", "api_key", apiKey.Name, "error", rateLimitErr)
+				httpResponseWriter.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+				http.Error(httpResponseWriter, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
+			default:
+				slog.ErrorContext(httpRequest.Context(), "Error al validar la clave de API", "error", authorizeErr)
+				http.Error(httpResponseWriter, "Could not validate API key", http.StatusInternalServerError)
+				return
+			}
+
+			httpResponseWriter.Header().Set("X-RateLimit-Limit", strconv.Itoa(keyUsage.Burst))
+			httpResponseWriter.Header().Set("X-RateLimit-Remaining", strconv.Itoa(keyUsage.RemainingBurst))
+			if keyUsage.DailyQuota > 0 {
+				httpResponseWriter.Header().Set("X-Quota-Limit", strconv.Itoa(keyUsage.DailyQuota))
+				httpResponseWriter.Header().Set("X-Quota-Remaining", strconv.Itoa(keyUsage.RemainingQuota))
+			}
+			nextHandler(httpResponseWriter, httpRequest.WithContext(context.WithValue(httpRequest.Context(), apiKeyContextKey{}, apiKey)))
+		}
+	}
+}
+
+// requestIDHeader es el encabezado con el que se recibe y se devuelve el ID de la petición.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength es el largo máximo aceptado para un ID de petición enviado por el cliente.
+const maxRequestIDLength = 64
+
+// RequestID retorna un middleware que asigna a cada petición un ID, lo devuelve en el encabezado "X-Request-ID"
+// y lo guarda en el contexto, para que las líneas de log de la petición lo incluyan.
+// Si el cliente (o un proxy) ya envió un ID válido, se reutiliza; si no, se genera uno nuevo.
+func RequestID(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		requestID := httpRequest.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = logging.NewID()
+		}
+		httpResponseWriter.Header().Set(requestIDHeader, requestID)
+		nextHandler.ServeHTTP(httpResponseWriter, httpRequest.WithContext(logging.WithRequestID(httpRequest.Context(), requestID)))
+	})
+}
+
+// validRequestID indica si un ID de petición recibido es aceptable: no vacío, corto y solo con caracteres
+// ASCII imprimibles, para que no se puedan inyectar saltos de línea ni valores enormes en los logs.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, character := range requestID {
+		if character <= ' ' || character > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder guarda el código de estado escrito por el manejador, para las métricas HTTP.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+// WriteHeader registra el código de estado antes de escribirlo.
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap permite a http.ResponseController llegar al ResponseWriter original.
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// TraceHTTP retorna un middleware que atiende cada petición dentro de un span de servidor, continuando la traza
+// recibida en el encabezado "traceparent". El span toma el nombre del patrón del ServeMux que atendió la petición.
+func TraceHTTP(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		requestContext := tracing.ExtractHeaders(httpRequest.Context(), httpRequest.Header)
+		requestContext, requestSpan := tracing.Tracer().Start(requestContext, httpRequest.Method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(httpRequest.Method), semconv.URLPath(httpRequest.URL.Path)))
+		defer requestSpan.End()
+		if requestID := logging.RequestIDFromContext(requestContext); requestID != "" {
+			requestSpan.SetAttributes(attribute.String("http.request.id", requestID))
+		}
+
+		tracedRequest := httpRequest.WithContext(requestContext)
+		recorder := &statusRecorder{ResponseWriter: httpResponseWriter, statusCode: http.StatusOK}
+		nextHandler.ServeHTTP(recorder, tracedRequest)
+
+		// El ServeMux deja el patrón elegido en la petición que recibió.
+		if tracedRequest.Pattern != "" {
+			requestSpan.SetName(tracedRequest.Pattern)
+			requestSpan.SetAttributes(semconv.HTTPRoute(tracedRequest.Pattern))
+		}
+		requestSpan.SetAttributes(semconv.HTTPResponseStatusCode(recorder.statusCode))
+		if recorder.statusCode >= http.StatusInternalServerError {
+			requestSpan.SetStatus(codes.Error, http.StatusText(recorder.statusCode))
+		}
+	})
+}
+
+// InstrumentHTTP retorna un middleware que registra la cantidad y la duración de las peticiones por ruta,
+// y deja una línea de log (nivel debug) por petición atendida.
+// La ruta es el patrón del ServeMux que atendió la petición (ej. "GET /admin/plans/{id}"), no la URL,
+// para que los IDs y fechas de las URLs no multipliquen las series; las peticiones sin ruta se agrupan en "unmatched".
+func InstrumentHTTP(nextHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		requestStart := time.Now()
+		recorder := &statusRecorder{ResponseWriter: httpResponseWriter, statusCode: http.StatusOK}
+		nextHandler.ServeHTTP(recorder, httpRequest)
+
+		// El ServeMux deja el patrón elegido en httpRequest.Pattern.
+		routePattern := httpRequest.Pattern
+		if routePattern == "" {
+			routePattern = "unmatched"
+		}
+		metrics.HTTPRequests.WithLabelValues(routePattern, httpRequest.Method, strconv.Itoa(recorder.statusCode)).Inc()
+		requestDuration := time.Since(requestStart)
+		metrics.HTTPRequestDuration.WithLabelValues(routePattern, httpRequest.Method).Observe(requestDuration.Seconds())
+		slog.DebugContext(httpRequest.Context(), "Petición HTTP atendida", "method", httpRequest.Method, "path", httpRequest.URL.Path, "route", routePattern, "status", recorder.statusCode, "duration", requestDuration)
+	})
+}
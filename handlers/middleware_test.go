@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+	"precio-bcv-go/services"
+	"precio-bcv-go/tracing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceHTTPSpans(t *testing.T) {
+	spanExporter := tracetest.NewInMemoryExporter()
+	tracerProvider, installErr := tracing.Install(spanExporter, "precio-bcv-test", 1)
+	if installErr != nil {
+		t.Fatal(installErr)
+	}
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+
+	// El manejador abre un span hijo con el contexto de la petición, como lo hacen los repositorios y las fuentes.
+	serveMux := http.NewServeMux()
+	serveMux.HandleFunc("GET /rate", func(httpResponseWriter http.ResponseWriter, httpRequest *http.Request) {
+		_, childSpan := tracing.Tracer().Start(httpRequest.Context(), "mongo.get_latest_bcv_rate")
+		childSpan.End()
+	})
+
+	const incomingTraceID, incomingSpanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	httpRequest := httptest.NewRequest(http.MethodGet, "/rate", nil)
+	httpRequest.Header.Set("traceparent", "00-"+incomingTraceID+"-"+incomingSpanID+"-01")
+	TraceHTTP(serveMux).ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if flushErr := tracerProvider.ForceFlush(context.Background()); flushErr != nil {
+		t.Fatal(flushErr)
+	}
+
+	spansByName := map[string]tracetest.SpanStub{}
+	for _, recordedSpan := range spanExporter.GetSpans() {
+		spansByName[recordedSpan.Name] = recordedSpan
+	}
+	serverSpan, serverFound := spansByName["GET /rate"]
+	childSpan, childFound := spansByName["mongo.get_latest_bcv_rate"]
+	if !serverFound || !childFound {
+		t.Fatalf("spans = %v; se esperaban 'GET /rate' y mongo.get_latest_bcv_rate", spanExporter.GetSpans().Snapshots())
+	}
+	// El span de la petición continúa la traza recibida en "traceparent".
+	if serverSpan.SpanContext.TraceID().String() != incomingTraceID || serverSpan.Parent.SpanID().String() != incomingSpanID {
+		t.Fatalf("span de la petición en la traza %s con padre %s; se esperaba %s con padre %s",
+			serverSpan.SpanContext.TraceID(), serverSpan.Parent.SpanID(), incomingTraceID, incomingSpanID)
+	}
+	if childSpan.Parent.SpanID() != serverSpan.SpanContext.SpanID() || childSpan.SpanContext.TraceID() != serverSpan.SpanContext.TraceID() {
+		t.Fatalf("el padre del span del manejador es %s; se esperaba el span de la petición %s", childSpan.Parent.SpanID(), serverSpan.SpanContext.SpanID())
+	}
+}
+
+func TestNewAdminAuthStoresCredential(t *testing.T) {
+	receivedCredential := ""
+	protectedHandler := NewAdminAuth("secreto")(func(_ http.ResponseWriter, httpRequest *http.Request) {
+		receivedCredential = AdminCredentialFromContext(httpRequest.Context())
+	})
+
+	httpRequest := httptest.NewRequest(http.MethodPut, "/admin/rates/2024-03-18", nil)
+	httpRequest.Header.Set("Authorization", "Bearer secreto")
+	protectedHandler(httptest.NewRecorder(), httpRequest)
+	if receivedCredential != adminTokenCredential("secreto") || receivedCredential == adminTokenCredential("otro") {
+		t.Fatalf("credencial = %q; se esperaba la huella del token configurado", receivedCredential)
+	}
+}
+
+func TestNewAPIKeyAuthAcceptsHeaderOnly(t *testing.T) {
+	apiKeyService := services.NewAPIKeyService(&config.Config{APIKeyDefaultRatePerMinute: 60, APIKeyDefaultBurst: 10}, services.NewMemoryAPIKeyRepository())
+	issuedKey, issueErr := apiKeyService.IssueAPIKey(context.Background(), models.APIKeyRequest{Name: "isp"})
+	if issueErr != nil {
+		t.Fatal(issueErr)
+	}
+	protectedHandler := NewAPIKeyAuth(apiKeyService, true)(func(httpResponseWriter http.ResponseWriter, _ *http.Request) {
+		httpResponseWriter.WriteHeader(http.StatusOK)
+	})
+
+	// La clave en la URL se ignora: quedaría registrada en los logs de acceso.
+	queryRecorder := httptest.NewRecorder()
+	protectedHandler(queryRecorder, httptest.NewRequest(http.MethodGet, "/bcv?api_key="+issuedKey.Key, nil))
+	if queryRecorder.Code != http.StatusUnauthorized {
+		t.Fatalf("con la clave en la URL: código de estado = %d; se esperaba %d", queryRecorder.Code, http.StatusUnauthorized)
+	}
+
+	headerRequest := httptest.NewRequest(http.MethodGet, "/bcv", nil)
+	headerRequest.Header.Set("X-API-Key", issuedKey.Key)
+	headerRecorder := httptest.NewRecorder()
+	protectedHandler(headerRecorder, headerRequest)
+	if headerRecorder.Code != http.StatusOK {
+		t.Fatalf("con la clave en X-API-Key: código de estado = %d; se esperaba %d", headerRecorder.Code, http.StatusOK)
+	}
+}
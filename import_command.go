@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"precio-bcv-go/services"
+)
+
+// importCommandUsage describe cómo invocar el comando de importación.
+const importCommandUsage = "uso: precio-bcv-go import <boletín.xls|boletín.xlsx> [...]"
+
+// runImportCommand importa los boletines históricos "tipo de cambio" del BCV indicados en 'bulletinPaths'
+// al almacenamiento de tasas configurado, y muestra cuántos días se insertaron, omitieron o quedaron en conflicto.
+func runImportCommand(rateRepository services.RateRepository, bulletinPaths []string) error {
+	if len(bulletinPaths) == 0 {
+		return fmt.Errorf("no se indicó ningún boletín. %s", importCommandUsage)
+	}
+
+	allPublications := []services.RatePublication{}
+	for _, bulletinPath := range bulletinPaths {
+		bulletinContent, readErr := os.ReadFile(bulletinPath)
+		if readErr != nil {
+			return fmt.Errorf("error al leer el boletín '%s': %w", bulletinPath, readErr)
+		}
+		publications, parseErr := services.ParseBCVBulletin(bulletinContent)
+		if parseErr != nil {
+			return fmt.Errorf("error al interpretar el boletín '%s': %w", bulletinPath, parseErr)
+		}
+		slog.Info("Boletín leído", "path", bulletinPath, "days", len(publications))
+		allPublications = append(allPublications, publications...)
+	}
+
+	importReport, importErr := services.ImportRatePublications(context.Background(), rateRepository, allPublications)
+	slog.Info("Importación de boletines terminada", "inserted", importReport.Inserted, "skipped", importReport.Skipped, "conflicting", importReport.Conflicting)
+	for _, conflictDetail := range importReport.Conflicts {
+		slog.Warn("Conflicto en la importación", "conflict", conflictDetail)
+	}
+	return importErr
+}
@@ -0,0 +1,115 @@
+// Package logging configura el logger estructurado (log/slog) de la aplicación y guarda en el contexto
+// los identificadores que correlacionan las líneas de una misma petición HTTP o actualización de tasas.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formatos de salida aceptados por NewLogger.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ParseLevel convierte un nivel en texto ("debug", "info", "warn" o "error") en un slog.Level.
+func ParseLevel(levelName string) (slog.Level, error) {
+	var parsedLevel slog.Level
+	if unmarshalErr := parsedLevel.UnmarshalText([]byte(strings.TrimSpace(levelName))); unmarshalErr != nil {
+		return slog.LevelInfo, fmt.Errorf("nivel de log inválido '%s' (use debug, info, warn o error)", levelName)
+	}
+	return parsedLevel, nil
+}
+
+// NewLogger crea un logger con el nivel y el formato ("text" o "json") indicados, escribiendo en 'output'.
+// Cada línea incluye los identificadores de correlación presentes en el contexto del llamador.
+func NewLogger(level slog.Level, format string, output io.Writer) (*slog.Logger, error) {
+	handlerOptions := &slog.HandlerOptions{Level: level}
+
+	var baseHandler slog.Handler
+	switch format {
+	case FormatText:
+		baseHandler = slog.NewTextHandler(output, handlerOptions)
+	case FormatJSON:
+		baseHandler = slog.NewJSONHandler(output, handlerOptions)
+	default:
+		return nil, fmt.Errorf("formato de log inválido '%s' (use text o json)", format)
+	}
+	return slog.New(contextHandler{Handler: baseHandler}), nil
+}
+
+// Fatal registra el mensaje con nivel de error y termina el proceso, como log.Fatalf.
+func Fatal(message string, attributes ...any) {
+	slog.Error(message, attributes...)
+	os.Exit(1)
+}
+
+// correlationKey identifica cada identificador de correlación guardado en el contexto.
+type correlationKey string
+
+// Identificadores de correlación, con el nombre del atributo con el que aparecen en cada línea.
+const (
+	requestIDKey correlationKey = "request_id"
+	runIDKey     correlationKey = "run_id"
+)
+
+// WithRequestID retorna un contexto con el ID de la petición HTTP.
+func WithRequestID(parentContext context.Context, requestID string) context.Context {
+	return context.WithValue(parentContext, requestIDKey, requestID)
+}
+
+// RequestIDFromContext retorna el ID de la petición HTTP guardado en el contexto, o "" si no hay.
+func RequestIDFromContext(requestContext context.Context) string {
+	requestID, _ := requestContext.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithRunID retorna un contexto con el ID de una ejecución de la actualización de tasas.
+func WithRunID(parentContext context.Context, runID string) context.Context {
+	return context.WithValue(parentContext, runIDKey, runID)
+}
+
+// NewID genera un identificador aleatorio de 16 caracteres hexadecimales.
+func NewID() string {
+	randomBytes := make([]byte, 8)
+	rand.Read(randomBytes)
+	return hex.EncodeToString(randomBytes)
+}
+
+// contextHandler agrega a cada registro los identificadores de correlación del contexto.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle agrega "request_id" y "run_id" al registro, si el contexto los tiene, y "trace_id" y "span_id"
+// si el contexto lleva un span de OpenTelemetry.
+func (handler contextHandler) Handle(logContext context.Context, logRecord slog.Record) error {
+	for _, correlation := range []correlationKey{requestIDKey, runIDKey} {
+		if correlationID, hasID := logContext.Value(correlation).(string); hasID && correlationID != "" {
+			logRecord.AddAttrs(slog.String(string(correlation), correlationID))
+		}
+	}
+	if spanContext := trace.SpanContextFromContext(logContext); spanContext.IsValid() {
+		logRecord.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return handler.Handler.Handle(logContext, logRecord)
+}
+
+// WithAttrs conserva el contextHandler al derivar loggers con atributos fijos.
+func (handler contextHandler) WithAttrs(attributes []slog.Attr) slog.Handler {
+	return contextHandler{Handler: handler.Handler.WithAttrs(attributes)}
+}
+
+// WithGroup conserva el contextHandler al derivar loggers con grupos.
+func (handler contextHandler) WithGroup(groupName string) slog.Handler {
+	return contextHandler{Handler: handler.Handler.WithGroup(groupName)}
+}
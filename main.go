@@ -1,17 +1,25 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	// Importaciones de tus módulos
 	"precio-bcv-go/config"
 	"precio-bcv-go/handlers"
+	"precio-bcv-go/logging"
+	"precio-bcv-go/metrics"
 	"precio-bcv-go/services"
+	"precio-bcv-go/tracing"
 
 	// Dependencias externas
 	gorillaHandlers "github.com/gorilla/handlers" // Alias para el paquete gorilla/handlers
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/robfig/cron"
 )
 
@@ -19,74 +27,234 @@ func main() {
 	// --- 1. Cargar Configuración de la Aplicación ---
 	// Carga la configuración desde variables de entorno o el archivo .env.
 	// La función retornará la configuración o un error fatal si falta algo esencial.
-	appConfig, configLoadErr := config.LoadConfig() 
+	appConfig, configLoadErr := config.LoadConfig()
 	if configLoadErr != nil {
-		log.Fatalf("Error crítico al cargar la configuración de la aplicación: %v", configLoadErr)
+		logging.Fatal("Error crítico al cargar la configuración de la aplicación", "error", configLoadErr)
 	}
-	log.Printf("Configuración cargada: Puerto=%s", appConfig.Port,)
 
-	// --- 2. Inicializar Servicio de Base de Datos MongoDB ---
-	// Crea una instancia del servicio que gestiona la conexión y operaciones con MongoDB,
-	// pasándole la configuración necesaria (URI, nombres de DB/Colección).
-	mongoService, mongoServiceInitErr := services.NewMongoDBService(appConfig) 
-	if mongoServiceInitErr != nil {
-		// Si la conexión a MongoDB falla (ej. servidor no disponible, credenciales incorrectas),
+	// Logger estructurado con el nivel y formato de LOG_LEVEL y LOG_FORMAT. Al ser el logger por defecto,
+	// también recibe lo que las dependencias escriben con el paquete log.
+	appLogger, loggerErr := logging.NewLogger(appConfig.LogLevel, appConfig.LogFormat, os.Stderr)
+	if loggerErr != nil {
+		logging.Fatal("Error crítico al configurar los logs", "error", loggerErr)
+	}
+	slog.SetDefault(appLogger)
+	slog.Info("Configuración cargada", "port", appConfig.Port, "log_level", appConfig.LogLevel.String(), "log_format", appConfig.LogFormat)
+
+	// Trazas de OpenTelemetry: con TRACING_EXPORTER=otlp los spans de las peticiones, el scrapeo, MongoDB y
+	// WhatsApp se exportan al colector OTLP; si no, solo se propaga el contexto de traza recibido.
+	shutdownTracing, tracingSetupErr := tracing.Setup(context.Background(), appConfig)
+	if tracingSetupErr != nil {
+		logging.Fatal("Error crítico al configurar las trazas", "error", tracingSetupErr)
+	}
+	slog.Info("Trazas configuradas", "exporter", appConfig.TracingExporter, "sample_ratio", appConfig.TracingSampleRatio)
+
+	// --- 2. Inicializar el Almacenamiento de Tasas ---
+	// Crea el repositorio indicado por STORAGE_BACKEND (MongoDB por defecto, memoria o bbolt),
+	// pasándole la configuración necesaria (URI, nombres de DB/Colección o ruta del archivo).
+	rateRepository, repositoryInitErr := services.NewRateRepository(appConfig)
+	if repositoryInitErr != nil {
+		// Si el almacenamiento no está disponible (ej. servidor MongoDB caído, archivo bloqueado),
 		// la aplicación no puede operar, por lo que se termina.
-		log.Fatalf("Error crítico: No se pudo inicializar el servicio de MongoDB: %v", mongoServiceInitErr)
+		logging.Fatal("Error crítico: No se pudo inicializar el almacenamiento de tasas", "backend", appConfig.StorageBackend, "error", repositoryInitErr)
 	}
-	// Asegura que la conexión a MongoDB se cierre de forma segura cuando la función main() finalice.
-	defer mongoService.Disconnect()
-	log.Println("Servicio de MongoDB inicializado y conexión establecida.")
+	slog.Info("Almacenamiento de tasas inicializado.", "backend", appConfig.StorageBackend)
+
+	// "import <boletines...>" carga los boletines históricos del BCV en el almacenamiento y termina,
+	// sin iniciar el servidor.
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importErr := runImportCommand(rateRepository, os.Args[2:])
+		closeContext, cancelClose := context.WithTimeout(context.Background(), appConfig.ShutdownGracePeriod)
+		rateRepository.Disconnect(closeContext)
+		shutdownTracing(closeContext)
+		cancelClose()
+		if importErr != nil {
+			logging.Fatal("Error al importar los boletines del BCV", "error", importErr)
+		}
+		return
+	}
+	// El almacenamiento se cierra en el apagado ordenado (paso 10), después de detener el servidor y el cron.
 
 	// --- 3. Inicializar Servicio de Tasa de Cambio BCV ---
 	// Crea una instancia del servicio que se encarga de obtener y mantener el valor del BCV.
-	// Se le inyecta el 'mongoService' para que pueda guardar los valores en la base de datos.
-	whatsAppService := services.NewWhatsAppService(appConfig) // Pasa la configuración
-	log.Println("Servicio de WhatsApp inicializado.")
+	// Se le inyecta el 'rateRepository' para que pueda guardar los valores en la base de datos.
+	// El dispatcher envía las alertas a cada canal habilitado (WhatsApp, Telegram, correo, Slack, webhook).
+	alertDispatcher := services.NewNotificationDispatcher(appConfig) // Pasa la configuración
+	// Los suscriptores (ej. ventas) reciben un aviso cada vez que se publica una nueva tasa.
+	rateSubscriberDispatcher := services.NewRateSubscriberDispatcher(appConfig)
+	slog.Info("Servicio de alertas inicializado.")
+
+	// Fuentes de tasas (página del BCV, boletín XLS, API JSON) en el orden de RATE_SOURCES.
+	rateSources, rateSourcesErr := services.NewRateSources(appConfig)
+	if rateSourcesErr != nil {
+		logging.Fatal("Error crítico: Configuración de fuentes de tasas inválida", "error", rateSourcesErr)
+	}
 
-	bcvPriceService := services.NewBCVService(mongoService, whatsAppService) // Renombrado: 'bcvService' -> 'bcvPriceService'
-	log.Println("Servicio de BCV inicializado.")
+	// Las tasas que superan la variación diaria máxima quedan en cuarentena, en el mismo backend que las tasas.
+	quarantineRepository, quarantineRepositoryErr := services.NewQuarantineRepository(rateRepository)
+	if quarantineRepositoryErr != nil {
+		logging.Fatal("Error crítico: No se pudo inicializar la cuarentena de tasas", "error", quarantineRepositoryErr)
+	}
+
+	bcvPriceService := services.NewBCVService(appConfig, rateRepository, alertDispatcher, rateSubscriberDispatcher, rateSources, quarantineRepository) // Renombrado: 'bcvService' -> 'bcvPriceService'
+	slog.Info("Servicio de BCV inicializado.")
+
+	// Motor de impuestos con perfiles con nombre (IVA, IGTF, exento) y fechas de vigencia.
+	taxEngine, taxEngineErr := services.NewTaxEngine(appConfig)
+	if taxEngineErr != nil {
+		logging.Fatal("Error crítico: No se pudieron cargar los perfiles de impuestos", "error", taxEngineErr)
+	}
+
+	// Catálogo de planes, guardado en el mismo backend que las tasas.
+	planRepository, planRepositoryErr := services.NewPlanRepository(rateRepository)
+	if planRepositoryErr != nil {
+		logging.Fatal("Error crítico: No se pudo inicializar el catálogo de planes", "error", planRepositoryErr)
+	}
+	planCatalogService, planServiceErr := services.NewPlanService(context.Background(), appConfig, planRepository, taxEngine)
+	if planServiceErr != nil {
+		logging.Fatal("Error crítico: No se pudo cargar el catálogo de planes", "error", planServiceErr)
+	}
+	slog.Info("Servicio de planes inicializado.")
+
+	// Claves de API de los clientes, con su límite por minuto y su cuota diaria, en el mismo backend que las tasas.
+	apiKeyRepository, apiKeyRepositoryErr := services.NewAPIKeyRepository(rateRepository)
+	if apiKeyRepositoryErr != nil {
+		logging.Fatal("Error crítico: No se pudo inicializar el almacenamiento de claves de API", "error", apiKeyRepositoryErr)
+	}
+	apiKeyService := services.NewAPIKeyService(appConfig, apiKeyRepository)
+
+	// Las señales de apagado se capturan desde antes de la primera actualización, que puede tardar varios
+	// reintentos: una señal recibida durante el arranque no mata el proceso a medias, sino que inicia el
+	// apagado ordenado (paso 10) en cuanto el servidor queda listo.
+	shutdownSignals := make(chan os.Signal, 1)
+	signal.Notify(shutdownSignals, syscall.SIGINT, syscall.SIGTERM)
 
 	// --- 4. Realizar la Primera Actualización de la Tasa BCV al Arrancar el Servidor ---
 	// Esto asegura que tengamos un valor inicial del BCV disponible antes de que lleguen
 	// las primeras peticiones HTTP, consultando primero la base de datos o scrapeando.
 	bcvPriceService.UpdateBCV()
-	log.Printf("Valor inicial del BCV establecido: %.4f\n", bcvPriceService.GetBCV())
+	slog.Info("Valor inicial del BCV establecido", "bcv", bcvPriceService.GetBCV())
 
 	// --- 5. Configurar Tarea Programada (Cron) para la Actualización Diaria del BCV ---
 	dailyPriceScheduler := cron.New()
-	dailyPriceScheduler.AddFunc("0 30 1 * * *", bcvPriceService.UpdateBCV) 
+	dailyPriceScheduler.AddFunc("0 30 1 * * *", bcvPriceService.UpdateBCV)
 	dailyPriceScheduler.Start()
-	log.Println("Con Activado")
+	slog.Info("Cron activado", "schedule", "0 30 1 * * *")
 
 	// --- 6. Configurar CORS (Cross-Origin Resource Sharing) para la API ---
 	// Define las políticas de seguridad para permitir solicitudes de diferentes dominios.
-	// Por ahora, se permite cualquier origen, cabecera y método para simplificar el desarrollo.
-	// En producción, es crucial restringir esto a dominios específicos.
-	corsAllowedOrigins := gorillaHandlers.AllowedOrigins([]string{"*"})  
-	corsAllowedHeaders := gorillaHandlers.AllowedHeaders([]string{"Content-Type"}) 
-	corsAllowedMethods := gorillaHandlers.AllowedMethods([]string{"GET", "OPTIONS"}) 
-	log.Println("Configuración de CORS aplicada (permitiendo todos los orígenes para desarrollo).")
+	// Los orígenes, cabeceras, métodos, credenciales y max-age vienen de las variables CORS_*;
+	// en producción, CORS_ALLOWED_ORIGINS debe restringirse a los dominios de los clientes.
+	corsOptions := []gorillaHandlers.CORSOption{
+		gorillaHandlers.AllowedOrigins(appConfig.CORSAllowedOrigins),
+		gorillaHandlers.AllowedHeaders(appConfig.CORSAllowedHeaders),
+		gorillaHandlers.AllowedMethods(appConfig.CORSAllowedMethods),
+		gorillaHandlers.MaxAge(appConfig.CORSMaxAgeSeconds),
+		gorillaHandlers.ExposedHeaders([]string{"X-Request-ID"}),
+	}
+	if appConfig.CORSAllowCredentials {
+		corsOptions = append(corsOptions, gorillaHandlers.AllowCredentials())
+	}
+	slog.Info("Configuración de CORS aplicada", "origins", appConfig.CORSAllowedOrigins, "methods", appConfig.CORSAllowedMethods, "credentials", appConfig.CORSAllowCredentials)
 
 	// --- 7. Inicializar Manejadores de Rutas API ---
 	// Crea una instancia de los manejadores HTTP que procesarán las solicitudes a las rutas de la API.
-	// Se le inyecta el 'bcvPriceService' para que los manejadores puedan acceder al valor del BCV.
-	apiRoutesHandlers := handlers.NewAPIHandlers(bcvPriceService) 
-	log.Println("Manejadores de API inicializados.")
+	// Se le inyectan el 'bcvPriceService', el catálogo de planes, el motor de impuestos y las claves de API para que los manejadores puedan acceder a ellos.
+	apiRoutesHandlers := handlers.NewAPIHandlers(bcvPriceService, planCatalogService, taxEngine, apiKeyService)
+	slog.Info("Manejadores de API inicializados.")
 
 	// --- 8. Configurar Rutas HTTP y sus Manejadores ---
 	// Asigna cada URL de la API a su función manejadora correspondiente.
 	// Cada manejador es un método de la instancia 'apiRoutesHandlers'.
-	http.HandleFunc("/", apiRoutesHandlers.HandleRequest)
-	http.HandleFunc("/plans", apiRoutesHandlers.HandlePlansRequest)
-	http.HandleFunc("/convert", apiRoutesHandlers.HandleConvertRequest)
-	log.Println("Rutas HTTP configuradas.")
+	// Las rutas públicas identifican al cliente por su clave de API (obligatoria si API_KEY_REQUIRED=true).
+	requireAPIKey := handlers.NewAPIKeyAuth(apiKeyService, appConfig.APIKeyRequired)
+	http.HandleFunc("/", requireAPIKey(apiRoutesHandlers.HandleRequest))
+	http.HandleFunc("/plans", requireAPIKey(apiRoutesHandlers.HandlePlansRequest))
+	http.HandleFunc("/convert", requireAPIKey(apiRoutesHandlers.HandleConvertRequest))
+	http.HandleFunc("/history", requireAPIKey(apiRoutesHandlers.HandleHistoryRequest))
+	http.HandleFunc("/rate", requireAPIKey(apiRoutesHandlers.HandleRateOnDateRequest))
+
+	// Sondas de Kubernetes; no requieren clave de API.
+	healthRoutesHandlers := handlers.NewHealthHandlers(bcvPriceService)
+	http.HandleFunc("GET /healthz", healthRoutesHandlers.HandleLiveness)
+	http.HandleFunc("GET /readyz", healthRoutesHandlers.HandleReadiness)
+
+	// Métricas de Prometheus (scrapeos, tasas, MongoDB, notificaciones y tráfico HTTP).
+	metrics.RegisterRateAge(func() time.Time {
+		return bcvPriceService.CurrentRates().EffectiveDate
+	})
+	http.Handle("GET /metrics", promhttp.Handler())
+
+	// Rutas de administración, protegidas con el token ADMIN_API_TOKEN.
+	requireAdmin := handlers.NewAdminAuth(appConfig.AdminAPIToken)
+	http.HandleFunc("GET /admin/plans", requireAdmin(apiRoutesHandlers.HandleAdminListPlans))
+	http.HandleFunc("POST /admin/plans", requireAdmin(apiRoutesHandlers.HandleAdminCreatePlan))
+	http.HandleFunc("GET /admin/plans/{id}", requireAdmin(apiRoutesHandlers.HandleAdminGetPlan))
+	http.HandleFunc("PUT /admin/plans/{id}", requireAdmin(apiRoutesHandlers.HandleAdminUpdatePlan))
+	http.HandleFunc("DELETE /admin/plans/{id}", requireAdmin(apiRoutesHandlers.HandleAdminDeletePlan))
+	http.HandleFunc("PUT /admin/rates/{date}", requireAdmin(apiRoutesHandlers.HandleAdminSetRate))
+	http.HandleFunc("DELETE /admin/rates/{date}", requireAdmin(apiRoutesHandlers.HandleAdminDeleteRate))
+	http.HandleFunc("GET /admin/history", requireAdmin(apiRoutesHandlers.HandleAdminHistoryRequest))
+	http.HandleFunc("GET /admin/quarantine", requireAdmin(apiRoutesHandlers.HandleAdminListQuarantine))
+	http.HandleFunc("POST /admin/quarantine/{id}/approve", requireAdmin(apiRoutesHandlers.HandleAdminApproveQuarantine))
+	http.HandleFunc("POST /admin/quarantine/{id}/reject", requireAdmin(apiRoutesHandlers.HandleAdminRejectQuarantine))
+	http.HandleFunc("GET /admin/api-keys", requireAdmin(apiRoutesHandlers.HandleAdminListAPIKeys))
+	http.HandleFunc("POST /admin/api-keys", requireAdmin(apiRoutesHandlers.HandleAdminIssueAPIKey))
+	http.HandleFunc("DELETE /admin/api-keys/{id}", requireAdmin(apiRoutesHandlers.HandleAdminRevokeAPIKey))
+	slog.Info("Rutas HTTP configuradas.")
 
 	// --- 9. Iniciar Servidor HTTP ---
 	// Comienza a escuchar en el puerto configurado y a procesar las solicitudes entrantes.
 	// Se aplica la configuración de CORS a todas las rutas usando el multiplexor HTTP por defecto.
-	fmt.Printf("Servidor iniciado y escuchando en el puerto %s\n", appConfig.Port)
-	http.ListenAndServe(":"+appConfig.Port, gorillaHandlers.CORS(corsAllowedOrigins, corsAllowedHeaders, corsAllowedMethods)(http.DefaultServeMux))
-	// log.Fatal es una función que, si ListenAndServe retorna un error (ej. el puerto ya está en uso),
-	// imprime el error y termina la aplicación.
-}
\ No newline at end of file
+	// Los timeouts evitan que clientes lentos o conexiones abandonadas retengan recursos indefinidamente.
+	httpServer := &http.Server{
+		Addr:         ":" + appConfig.Port,
+		Handler:      handlers.RequestID(gorillaHandlers.CORS(corsOptions...)(handlers.TraceHTTP(handlers.InstrumentHTTP(http.DefaultServeMux)))),
+		ReadTimeout:  appConfig.HTTPReadTimeout,
+		WriteTimeout: appConfig.HTTPWriteTimeout,
+		IdleTimeout:  appConfig.HTTPIdleTimeout,
+	}
+	serverErrors := make(chan error, 1)
+	go func() {
+		slog.Info("Servidor iniciado", "port", appConfig.Port)
+		serverErrors <- httpServer.ListenAndServe()
+	}()
+
+	// --- 10. Apagado Ordenado ---
+	// Al recibir SIGINT o SIGTERM se deja de aceptar conexiones, se esperan las peticiones en curso,
+	// se detienen el cron y los reintentos programados, se cierra el almacenamiento y por último se envían los spans pendientes.
+	// Todo el proceso comparte el plazo SHUTDOWN_GRACE_PERIOD. Las señales se capturan desde el paso 4.
+	select {
+	case serveErr := <-serverErrors:
+		// ListenAndServe solo retorna antes de Shutdown por un error (ej. el puerto ya está en uso).
+		dailyPriceScheduler.Stop()
+		closeContext, cancelClose := context.WithTimeout(context.Background(), appConfig.ShutdownGracePeriod)
+		rateRepository.Disconnect(closeContext)
+		cancelClose()
+		logging.Fatal("Error crítico: El servidor HTTP se detuvo", "error", serveErr)
+	case receivedSignal := <-shutdownSignals:
+		slog.Info("Señal recibida. Iniciando apagado ordenado...", "signal", receivedSignal.String(), "grace_period", appConfig.ShutdownGracePeriod.String())
+	}
+
+	shutdownContext, cancelShutdown := context.WithTimeout(context.Background(), appConfig.ShutdownGracePeriod)
+	defer cancelShutdown()
+
+	if shutdownErr := httpServer.Shutdown(shutdownContext); shutdownErr != nil {
+		slog.Warn("No todas las peticiones en curso terminaron a tiempo", "error", shutdownErr)
+	} else {
+		slog.Info("Servidor HTTP detenido.")
+	}
+
+	dailyPriceScheduler.Stop()
+	slog.Info("Cron detenido.")
+	if stopErr := bcvPriceService.Stop(shutdownContext); stopErr != nil {
+		slog.Warn("La actualización de BCV en curso no terminó a tiempo", "error", stopErr)
+	}
+
+	rateRepository.Disconnect(shutdownContext)
+	// Los últimos spans (incluidos los del cierre) se envían antes de terminar.
+	if tracingShutdownErr := shutdownTracing(shutdownContext); tracingShutdownErr != nil {
+		slog.Warn("No se pudieron enviar los últimos spans", "error", tracingShutdownErr)
+	}
+	slog.Info("Apagado completado.")
+}
@@ -0,0 +1,130 @@
+// Package metrics define las métricas de Prometheus expuestas en /metrics.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/shopspring/decimal"
+)
+
+// namespace antecede al nombre de todas las métricas de la aplicación.
+const namespace = "bcv"
+
+var (
+	// RateFetchAttempts cuenta las consultas a cada fuente de tasas.
+	RateFetchAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_fetch_attempts_total",
+		Help:      "Consultas a cada fuente de tasas.",
+	}, []string{"source"})
+
+	// RateFetchResults cuenta el resultado ("success" o "error") de cada consulta a una fuente de tasas.
+	RateFetchResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_fetch_results_total",
+		Help:      "Resultado de las consultas a cada fuente de tasas.",
+	}, []string{"source", "outcome"})
+
+	// RateFetchDuration mide la duración de cada consulta a una fuente de tasas.
+	RateFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rate_fetch_duration_seconds",
+		Help:      "Duración de las consultas a cada fuente de tasas.",
+		Buckets:   []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+	}, []string{"source"})
+
+	// RateUpdates cuenta las actualizaciones de tasas según de dónde salió la tasa resultante:
+	// "db_today", "scraped", "quarantined", "unverified" (no se pudo verificar la variación diaria), "db_fallback" o "failed".
+	RateUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_updates_total",
+		Help:      "Actualizaciones de tasas según su resultado.",
+	}, []string{"outcome"})
+
+	// CurrentRate es la tasa actual, en bolívares, de cada moneda.
+	CurrentRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "current_rate",
+		Help:      "Tasa actual del BCV, en bolívares por unidad de cada moneda.",
+	}, []string{"currency"})
+
+	// MongoOperationDuration mide la latencia de cada operación sobre MongoDB.
+	MongoOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "Latencia de las operaciones sobre MongoDB.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 10},
+	}, []string{"operation"})
+
+	// NotificationsSent cuenta los envíos de alertas y avisos por canal (ej. "whatsapp") y resultado.
+	NotificationsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_sent_total",
+		Help:      "Envíos de alertas y avisos por canal y resultado.",
+	}, []string{"channel", "outcome"})
+
+	// HTTPRequests cuenta las peticiones HTTP por ruta, método y código de estado.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Peticiones HTTP por ruta, método y código de estado.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration mide la duración de las peticiones HTTP por ruta y método.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duración de las peticiones HTTP por ruta y método.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+// Outcome retorna "success" si 'operationErr' es nil, o "error" en caso contrario.
+func Outcome(operationErr error) string {
+	if operationErr != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// ObserveMongoOperation registra la latencia de una operación sobre MongoDB iniciada en 'startTime'.
+// Se usa con defer al inicio de la operación: defer metrics.ObserveMongoOperation("save_rate", time.Now()).
+func ObserveMongoOperation(operation string, startTime time.Time) {
+	MongoOperationDuration.WithLabelValues(operation).Observe(time.Since(startTime).Seconds())
+}
+
+// SetCurrentRates actualiza el gauge de tasas actuales, eliminando las monedas que ya no tienen tasa.
+// Prometheus solo admite float64, por lo que el gauge puede diferir de la tasa exacta en los últimos dígitos.
+func SetCurrentRates(currentRates map[string]decimal.Decimal) {
+	CurrentRate.Reset()
+	for currency, currencyRate := range currentRates {
+		CurrentRate.WithLabelValues(currency).Set(currencyRate.InexactFloat64())
+	}
+}
+
+// RegisterRateAge registra los gauges con la fecha valor de la tasa actual y su antigüedad en segundos.
+// 'currentRateDate' se consulta en cada scrape de Prometheus; si retorna la fecha cero, ambos valen 0.
+func RegisterRateAge(currentRateDate func() time.Time) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "current_rate_effective_date_seconds",
+		Help:      "Fecha valor de la tasa actual, en segundos desde la época Unix.",
+	}, func() float64 {
+		if rateDate := currentRateDate(); !rateDate.IsZero() {
+			return float64(rateDate.Unix())
+		}
+		return 0
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "current_rate_age_seconds",
+		Help:      "Antigüedad de la fecha valor de la tasa actual, en segundos.",
+	}, func() float64 {
+		if rateDate := currentRateDate(); !rateDate.IsZero() {
+			return time.Since(rateDate).Seconds()
+		}
+		return 0
+	})
+}
@@ -1,27 +1,238 @@
+// Package models define los cuerpos de las respuestas de la API y los documentos que se guardan.
+// Las tasas y los montos son decimales exactos (decimal.Decimal), no float64, para que los precios no acumulen
+// errores de redondeo. En JSON se serializan como cadenas (ej. "36.5"), y en MongoDB como Decimal128.
 package models
 
-import "time" // Necesario para el campo Timestamp de BCVRate
+import (
+	"time" // Necesario para el campo Timestamp de BCVRate
+
+	"github.com/shopspring/decimal"
+)
+
+// RateMetadata indica de qué tasas sale una respuesta: su fecha valor, cuándo y de dónde se obtuvieron
+// ("scrape", "db-today", "db-fallback" o "manual") y si están desactualizadas
+type RateMetadata struct {
+	RateDate  string    `json:"rate_date"` // Fecha valor (YYYY-MM-DD)
+	FetchedAt time.Time `json:"fetched_at"`
+	Source    string    `json:"source"`
+	Stale     bool      `json:"stale"`
+}
 
 // Response para la ruta principal
 type Response struct {
-	BCV float64 `json:"bcv"`
+	BCV      decimal.Decimal `json:"bcv"`
+	Currency string          `json:"currency"`
+	RateMetadata
 }
 
 // PlansResponse para la ruta /plans
 type PlansResponse struct {
-	Price20 float64 `json:"price_20"`
-	Price25 float64 `json:"price_25"`
-	Price30 float64 `json:"price_30"`
+	Plans []PlanPrice `json:"plans"`
+	RateMetadata
+}
+
+// PlanPrice es el precio en bolívares de un plan activo del catálogo
+type PlanPrice struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	PriceUSD   decimal.Decimal `json:"price_usd"`
+	TaxProfile string          `json:"tax_profile"`
+	Price      decimal.Decimal `json:"price"`
+	Breakdown  TaxBreakdown    `json:"breakdown"`
+}
+
+// Plan representa un plan del catálogo, guardado en la colección de planes
+type Plan struct {
+	ID         string          `json:"id" bson:"_id"`
+	Name       string          `json:"name" bson:"name"`
+	PriceUSD   decimal.Decimal `json:"price_usd" bson:"price_usd"`
+	TaxProfile string          `json:"tax_profile" bson:"tax_profile"`
+	Active     bool            `json:"active" bson:"active"`
 }
 
 // ConversionResponse para la ruta /convert
 type ConversionResponse struct {
-	Conversion float64 `json:"conversion"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Amount     decimal.Decimal `json:"amount"`
+	Rate       decimal.Decimal `json:"rate"`                // Unidades de "to" por cada unidad de "from", con 8 decimales
+	Conversion decimal.Decimal `json:"conversion"`          // Redondeada a 2 decimales
+	Breakdown  *TaxBreakdown   `json:"breakdown,omitempty"` // Solo cuando se pide un perfil de impuestos
+	RateMetadata
+}
+
+// TaxBreakdown detalla el cálculo de un monto: base imponible, cada impuesto y total
+type TaxBreakdown struct {
+	TaxProfile string          `json:"tax_profile"`
+	Base       decimal.Decimal `json:"base"`
+	Taxes      []TaxLineItem   `json:"taxes"`
+	Total      decimal.Decimal `json:"total"`
+}
+
+// TaxLineItem es el monto de un impuesto dentro de un TaxBreakdown
+type TaxLineItem struct {
+	Code    string          `json:"code"`
+	Name    string          `json:"name"`
+	Percent decimal.Decimal `json:"percent"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+// TaxProfile es un perfil de impuestos con nombre (ej. "iva_16_igtf"), con una versión por cada decreto
+type TaxProfile struct {
+	Name     string              `json:"name"`
+	Versions []TaxProfileVersion `json:"versions"`
+}
+
+// TaxProfileVersion son los impuestos de un perfil vigentes desde una fecha (YYYY-MM-DD; vacía = siempre)
+type TaxProfileVersion struct {
+	EffectiveFrom string    `json:"effective_from"`
+	Taxes         []TaxRule `json:"taxes"`
+}
+
+// TaxRule es un impuesto dentro de un perfil. Si Compound es true, se calcula sobre la base
+// más los impuestos anteriores (como el IGTF, que grava el monto total pagado)
+type TaxRule struct {
+	Code     string          `json:"code"`
+	Name     string          `json:"name"`
+	Percent  decimal.Decimal `json:"percent"` // Acepta un número o una cadena (ej. 16 o "16")
+	Compound bool            `json:"compound"`
+}
+
+// HistoryPage describe el rango y la página de una consulta del historial de tasas
+type HistoryPage struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Total    int64  `json:"total"`
+}
+
+// HistoryResponse para la ruta /history. Solo incluye los datos públicos de cada registro (ver HistoryRate)
+type HistoryResponse struct {
+	HistoryPage
+	Rates []HistoryRate `json:"rates"`
+}
+
+// AdminHistoryResponse para la ruta /admin/history, con los registros completos, incluida su auditoría
+type AdminHistoryResponse struct {
+	HistoryPage
+	Rates []BCVRate `json:"rates"`
+}
+
+// HistoryRate es la vista pública de un registro de tasas, sin los datos de auditoría de las cargas manuales
+type HistoryRate struct {
+	Value         decimal.Decimal            `json:"value"`
+	Rates         map[string]decimal.Decimal `json:"rates,omitempty"`
+	EffectiveDate time.Time                  `json:"effective_date,omitempty"`
+	Timestamp     time.Time                  `json:"timestamp"`
+	Source        string                     `json:"source,omitempty"`
+}
+
+// RateOnDateResponse para la ruta /rate
+type RateOnDateResponse struct {
+	Date          string          `json:"date"`
+	EffectiveDate string          `json:"effective_date"`
+	Currency      string          `json:"currency"`
+	BCV           decimal.Decimal `json:"bcv"`
+}
+
+// DependencyStatus es el estado de una dependencia en la respuesta de /readyz.
+type DependencyStatus struct {
+	Status string `json:"status"` // "ok" o "error".
+	Error  string `json:"error,omitempty"`
+}
+
+// RateStatus es el estado de la tasa actual en la respuesta de /readyz.
+type RateStatus struct {
+	DependencyStatus
+	BCV      decimal.Decimal `json:"bcv"`
+	RateDate *time.Time      `json:"rate_date,omitempty"`
+	Age      string          `json:"age,omitempty"`
+}
+
+// ReadinessResponse para la ruta /readyz
+type ReadinessResponse struct {
+	Status               string           `json:"status"` // "ok" o "unavailable".
+	Storage              DependencyStatus `json:"storage"`
+	Rate                 RateStatus       `json:"rate"`
+	LastSuccessfulScrape *time.Time       `json:"last_successful_scrape"`
 }
 
 // BCVRate representa el documento que se guardará en MongoDB
 type BCVRate struct {
-	ID        string    `json:"id,omitempty" bson:"_id,omitempty"` // Opcional para MongoDB, usa ObjectID
-	Value     float64   `json:"value" bson:"value"`
-	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
-}
\ No newline at end of file
+	ID            string                     `json:"id,omitempty" bson:"_id,omitempty"` // Opcional para MongoDB, usa ObjectID
+	Value         decimal.Decimal            `json:"value" bson:"value"`                // Tasa del dólar; se mantiene por compatibilidad con documentos anteriores.
+	Rates         map[string]decimal.Decimal `json:"rates,omitempty" bson:"rates,omitempty"`
+	EffectiveDate time.Time                  `json:"effective_date,omitempty" bson:"effective_date,omitempty"` // "Fecha Valor" publicada por el BCV
+	Timestamp     time.Time                  `json:"timestamp" bson:"timestamp"`
+	Source        string                     `json:"source,omitempty" bson:"source,omitempty"`         // Fuente de las tasas (ej. "bcv_page", "manual")
+	Author        string                     `json:"author,omitempty" bson:"author,omitempty"`         // Nombre declarado en la petición de una carga manual; no está verificado
+	Credential    string                     `json:"credential,omitempty" bson:"credential,omitempty"` // Token de administración usado en la carga manual (ej. "admin-token:3f2a9c0b1d4e"); no distingue personas
+	Note          string                     `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// PublicView retorna los datos del registro que pueden mostrarse a cualquier cliente de la API.
+// Author, Credential y Note solo se muestran en las rutas de administración.
+func (rate BCVRate) PublicView() HistoryRate {
+	return HistoryRate{
+		Value:         rate.Value,
+		Rates:         rate.Rates,
+		EffectiveDate: rate.EffectiveDate,
+		Timestamp:     rate.Timestamp,
+		Source:        rate.Source,
+	}
+}
+
+// RateFor retorna la tasa guardada para la moneda indicada (código ISO, ej. "EUR").
+// Los documentos anteriores solo tienen el campo Value, que corresponde al dólar.
+func (rate BCVRate) RateFor(currency string) decimal.Decimal {
+	if currencyRate, exists := rate.Rates[currency]; exists {
+		return currencyRate
+	}
+	if currency == "USD" {
+		return rate.Value
+	}
+	return decimal.Zero
+}
+
+// QuarantinedRate es una publicación de tasas retenida por superar la variación diaria máxima,
+// guardada en la colección de cuarentena hasta que un administrador la apruebe o la rechace
+type QuarantinedRate struct {
+	ID            string                     `json:"id" bson:"_id"`
+	Source        string                     `json:"source" bson:"source"`
+	Rates         map[string]decimal.Decimal `json:"rates" bson:"rates"`
+	PreviousRates map[string]decimal.Decimal `json:"previous_rates" bson:"previous_rates"`
+	EffectiveDate time.Time                  `json:"effective_date" bson:"effective_date"`
+	Reason        string                     `json:"reason" bson:"reason"`
+	Status        string                     `json:"status" bson:"status"` // "pending", "approved" o "rejected"
+	QuarantinedAt time.Time                  `json:"quarantined_at" bson:"quarantined_at"`
+	ReviewedAt    *time.Time                 `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+}
+
+// APIKey es una clave de acceso emitida a un cliente (ej. un ISP aliado), guardada en la colección de claves.
+// Solo se guarda el hash SHA-256 de la clave; la clave en claro se muestra una única vez al emitirla
+type APIKey struct {
+	ID            string     `json:"id" bson:"_id"`
+	Name          string     `json:"name" bson:"name"`
+	KeyHash       string     `json:"-" bson:"key_hash"`
+	KeyPrefix     string     `json:"key_prefix" bson:"key_prefix"` // Primeros caracteres de la clave, para identificarla
+	RatePerMinute float64    `json:"rate_per_minute" bson:"rate_per_minute"`
+	Burst         int        `json:"burst" bson:"burst"`
+	DailyQuota    int        `json:"daily_quota" bson:"daily_quota"` // 0 = sin límite diario
+	CreatedAt     time.Time  `json:"created_at" bson:"created_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// IssuedAPIKey es la respuesta al emitir una clave: incluye la clave en claro, que no se vuelve a mostrar
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyRequest es el cuerpo para emitir una clave; los límites omitidos toman los valores por defecto
+type APIKeyRequest struct {
+	Name          string   `json:"name"`
+	RatePerMinute *float64 `json:"rate_per_minute"`
+	Burst         *int     `json:"burst"`
+	DailyQuota    *int     `json:"daily_quota"`
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"precio-bcv-go/models"
+)
+
+// APIKeyRepository define las operaciones de almacenamiento de las claves de API.
+type APIKeyRepository interface {
+	ListAPIKeys(operationContext context.Context) ([]models.APIKey, error)
+	GetAPIKey(operationContext context.Context, apiKeyID string) (*models.APIKey, error)
+	GetAPIKeyByHash(operationContext context.Context, keyHash string) (*models.APIKey, error)
+	SaveAPIKey(operationContext context.Context, apiKey models.APIKey) error
+}
+
+// Verificaciones en tiempo de compilación de que cada backend implementa APIKeyRepository.
+var (
+	_ APIKeyRepository = (*MongoDBService)(nil)
+	_ APIKeyRepository = (*MemoryAPIKeyRepository)(nil)
+	_ APIKeyRepository = (*BoltAPIKeyRepository)(nil)
+)
+
+// NewAPIKeyRepository retorna el repositorio de claves de API que comparte el backend del repositorio de tasas.
+func NewAPIKeyRepository(rateRepository RateRepository) (APIKeyRepository, error) {
+	switch typedRepository := rateRepository.(type) {
+	case *MongoDBService:
+		return typedRepository, nil
+	case *BoltRateRepository:
+		return NewBoltAPIKeyRepository(typedRepository.database)
+	case *MemoryRateRepository:
+		return NewMemoryAPIKeyRepository(), nil
+	default:
+		return nil, fmt.Errorf("el backend de almacenamiento %T no soporta claves de API", rateRepository)
+	}
+}
+
+// sortAPIKeys ordena las claves de la más antigua a la más reciente.
+func sortAPIKeys(apiKeys []models.APIKey) {
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].CreatedAt.Before(apiKeys[j].CreatedAt)
+	})
+}
+
+// MemoryAPIKeyRepository guarda las claves de API en memoria.
+type MemoryAPIKeyRepository struct {
+	apiKeysMutex sync.RWMutex
+	apiKeys      map[string]models.APIKey
+}
+
+// NewMemoryAPIKeyRepository crea un repositorio de claves vacío en memoria.
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{apiKeys: map[string]models.APIKey{}}
+}
+
+// ListAPIKeys retorna todas las claves, vigentes o revocadas.
+func (repository *MemoryAPIKeyRepository) ListAPIKeys(operationContext context.Context) ([]models.APIKey, error) {
+	repository.apiKeysMutex.RLock()
+	defer repository.apiKeysMutex.RUnlock()
+
+	apiKeys := make([]models.APIKey, 0, len(repository.apiKeys))
+	for _, apiKey := range repository.apiKeys {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sortAPIKeys(apiKeys)
+	return apiKeys, nil
+}
+
+// GetAPIKey retorna la clave con el ID indicado, o nil si no existe.
+func (repository *MemoryAPIKeyRepository) GetAPIKey(operationContext context.Context, apiKeyID string) (*models.APIKey, error) {
+	repository.apiKeysMutex.RLock()
+	defer repository.apiKeysMutex.RUnlock()
+
+	apiKey, exists := repository.apiKeys[apiKeyID]
+	if !exists {
+		return nil, nil
+	}
+	return &apiKey, nil
+}
+
+// GetAPIKeyByHash retorna la clave con el hash indicado, o nil si no existe.
+func (repository *MemoryAPIKeyRepository) GetAPIKeyByHash(operationContext context.Context, keyHash string) (*models.APIKey, error) {
+	repository.apiKeysMutex.RLock()
+	defer repository.apiKeysMutex.RUnlock()
+
+	for _, apiKey := range repository.apiKeys {
+		if apiKey.KeyHash == keyHash {
+			return &apiKey, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveAPIKey crea o reemplaza la clave con su ID.
+func (repository *MemoryAPIKeyRepository) SaveAPIKey(operationContext context.Context, apiKey models.APIKey) error {
+	repository.apiKeysMutex.Lock()
+	defer repository.apiKeysMutex.Unlock()
+	repository.apiKeys[apiKey.ID] = apiKey
+	return nil
+}
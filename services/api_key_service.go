@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+)
+
+// apiKeyPrefix antecede a cada clave emitida, para reconocerlas en configuraciones y registros.
+const apiKeyPrefix = "bcv_"
+
+// Errores retornados por APIKeyService, para que los manejadores HTTP elijan el código de estado.
+var (
+	ErrInvalidAPIKey        = errors.New("clave de API inválida o revocada")
+	ErrAPIKeyNotFound       = errors.New("clave de API no encontrada")
+	ErrInvalidAPIKeyRequest = errors.New("solicitud de clave de API inválida")
+)
+
+// RateLimitError indica que una clave superó su ritmo de peticiones o su cuota diaria.
+type RateLimitError struct {
+	RetryAfter    time.Duration
+	QuotaExceeded bool // true si se agotó la cuota diaria; false si solo se superó el ritmo por minuto.
+}
+
+func (rateLimitErr *RateLimitError) Error() string {
+	if rateLimitErr.QuotaExceeded {
+		return "cuota diaria de la clave de API agotada"
+	}
+	return fmt.Sprintf("límite de peticiones de la clave de API superado; reintente en %s", rateLimitErr.RetryAfter)
+}
+
+// APIKeyUsage es el estado de los límites de una clave tras una petición autorizada.
+type APIKeyUsage struct {
+	Burst          int
+	RemainingBurst int
+	DailyQuota     int // 0 = sin límite diario.
+	RemainingQuota int
+}
+
+// apiKeyCounters guarda el token bucket y el conteo diario de una clave.
+type apiKeyCounters struct {
+	bucket        *tokenBucket
+	usageDay      string
+	requestsToday int
+}
+
+// APIKeyService emite, revoca y valida claves de API, y aplica a cada clave su límite por minuto
+// (token bucket) y su cuota diaria. Los límites son por instancia: los contadores viven en la memoria del
+// proceso, no se comparten entre réplicas y se reinician con cada despliegue. Con N réplicas detrás de un
+// balanceador, una clave puede hacer hasta N veces su límite y su cuota diaria.
+type APIKeyService struct {
+	apiKeyRepository     APIKeyRepository
+	defaultRatePerMinute float64
+	defaultBurst         int
+	defaultDailyQuota    int
+
+	countersMutex sync.Mutex
+	countersByKey map[string]*apiKeyCounters
+}
+
+// NewAPIKeyService crea el servicio de claves de API con los límites por defecto de la configuración.
+func NewAPIKeyService(appConfig *config.Config, apiKeyRepository APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepository:     apiKeyRepository,
+		defaultRatePerMinute: appConfig.APIKeyDefaultRatePerMinute,
+		defaultBurst:         appConfig.APIKeyDefaultBurst,
+		defaultDailyQuota:    appConfig.APIKeyDefaultDailyQuota,
+		countersByKey:        map[string]*apiKeyCounters{},
+	}
+}
+
+// ListAPIKeys retorna todas las claves emitidas, vigentes o revocadas, sin la clave en claro.
+func (service *APIKeyService) ListAPIKeys(requestContext context.Context) ([]models.APIKey, error) {
+	return service.apiKeyRepository.ListAPIKeys(requestContext)
+}
+
+// IssueAPIKey emite una clave nueva para el cliente indicado. La clave en claro solo se retorna aquí;
+// en la base de datos se guarda su hash SHA-256.
+func (service *APIKeyService) IssueAPIKey(requestContext context.Context, keyRequest models.APIKeyRequest) (models.IssuedAPIKey, error) {
+	apiKey := models.APIKey{
+		Name:          strings.TrimSpace(keyRequest.Name),
+		RatePerMinute: service.defaultRatePerMinute,
+		Burst:         service.defaultBurst,
+		DailyQuota:    service.defaultDailyQuota,
+		CreatedAt:     time.Now(),
+	}
+	if keyRequest.RatePerMinute != nil {
+		apiKey.RatePerMinute = *keyRequest.RatePerMinute
+	}
+	if keyRequest.Burst != nil {
+		apiKey.Burst = *keyRequest.Burst
+	}
+	if keyRequest.DailyQuota != nil {
+		apiKey.DailyQuota = *keyRequest.DailyQuota
+	}
+
+	switch {
+	case apiKey.Name == "":
+		return models.IssuedAPIKey{}, fmt.Errorf("%w: el nombre es requerido", ErrInvalidAPIKeyRequest)
+	case apiKey.RatePerMinute <= 0 || apiKey.Burst < 1:
+		return models.IssuedAPIKey{}, fmt.Errorf("%w: rate_per_minute debe ser mayor que 0 y burst al menos 1", ErrInvalidAPIKeyRequest)
+	case apiKey.DailyQuota < 0:
+		return models.IssuedAPIKey{}, fmt.Errorf("%w: daily_quota no puede ser negativa", ErrInvalidAPIKeyRequest)
+	}
+
+	keySecret, secretErr := randomHex(16)
+	if secretErr != nil {
+		return models.IssuedAPIKey{}, secretErr
+	}
+	apiKeyID, idErr := randomHex(8)
+	if idErr != nil {
+		return models.IssuedAPIKey{}, idErr
+	}
+	plainKey := apiKeyPrefix + keySecret
+	apiKey.ID = apiKeyID
+	apiKey.KeyHash = hashAPIKey(plainKey)
+	apiKey.KeyPrefix = plainKey[:len(apiKeyPrefix)+8]
+	if saveErr := service.apiKeyRepository.SaveAPIKey(requestContext, apiKey); saveErr != nil {
+		return models.IssuedAPIKey{}, saveErr
+	}
+
+	slog.Info("Clave de API emitida", "name", apiKey.Name, "key_prefix", apiKey.KeyPrefix)
+	return models.IssuedAPIKey{APIKey: apiKey, Key: plainKey}, nil
+}
+
+// RevokeAPIKey revoca la clave indicada; deja de ser aceptada de inmediato.
+// Revocar una clave ya revocada no la modifica.
+func (service *APIKeyService) RevokeAPIKey(requestContext context.Context, apiKeyID string) (models.APIKey, error) {
+	apiKey, getErr := service.apiKeyRepository.GetAPIKey(requestContext, apiKeyID)
+	if getErr != nil {
+		return models.APIKey{}, getErr
+	}
+	if apiKey == nil {
+		return models.APIKey{}, ErrAPIKeyNotFound
+	}
+	if apiKey.RevokedAt != nil {
+		return *apiKey, nil
+	}
+
+	revokedAt := time.Now()
+	apiKey.RevokedAt = &revokedAt
+	if saveErr := service.apiKeyRepository.SaveAPIKey(requestContext, *apiKey); saveErr != nil {
+		return models.APIKey{}, saveErr
+	}
+
+	service.countersMutex.Lock()
+	delete(service.countersByKey, apiKey.ID)
+	service.countersMutex.Unlock()
+
+	slog.Info("Clave de API revocada", "name", apiKey.Name, "key_prefix", apiKey.KeyPrefix)
+	return *apiKey, nil
+}
+
+// Authorize valida una clave en claro y descuenta una petición de sus límites.
+// Retorna ErrInvalidAPIKey si la clave no existe o está revocada, o un *RateLimitError si superó sus límites.
+func (service *APIKeyService) Authorize(requestContext context.Context, plainKey string) (models.APIKey, APIKeyUsage, error) {
+	apiKey, getErr := service.apiKeyRepository.GetAPIKeyByHash(requestContext, hashAPIKey(plainKey))
+	if getErr != nil {
+		return models.APIKey{}, APIKeyUsage{}, getErr
+	}
+	if apiKey == nil || apiKey.RevokedAt != nil {
+		return models.APIKey{}, APIKeyUsage{}, ErrInvalidAPIKey
+	}
+
+	service.countersMutex.Lock()
+	defer service.countersMutex.Unlock()
+
+	currentTime := time.Now()
+	keyCounters, exists := service.countersByKey[apiKey.ID]
+	if !exists {
+		keyCounters = &apiKeyCounters{bucket: newTokenBucket(apiKey.Burst, apiKey.RatePerMinute/60, currentTime)}
+		service.countersByKey[apiKey.ID] = keyCounters
+	}
+	if currentDay := currentTime.In(time.Local).Format("2006-01-02"); keyCounters.usageDay != currentDay {
+		keyCounters.usageDay = currentDay
+		keyCounters.requestsToday = 0
+	}
+
+	if apiKey.DailyQuota > 0 && keyCounters.requestsToday >= apiKey.DailyQuota {
+		localTime := currentTime.In(time.Local)
+		nextDay := time.Date(localTime.Year(), localTime.Month(), localTime.Day()+1, 0, 0, 0, 0, localTime.Location())
+		return *apiKey, APIKeyUsage{}, &RateLimitError{RetryAfter: nextDay.Sub(currentTime), QuotaExceeded: true}
+	}
+	if allowed, retryAfter := keyCounters.bucket.take(currentTime); !allowed {
+		return *apiKey, APIKeyUsage{}, &RateLimitError{RetryAfter: retryAfter}
+	}
+	keyCounters.requestsToday++
+
+	keyUsage := APIKeyUsage{
+		Burst:          apiKey.Burst,
+		RemainingBurst: keyCounters.bucket.remaining(),
+		DailyQuota:     apiKey.DailyQuota,
+	}
+	if apiKey.DailyQuota > 0 {
+		keyUsage.RemainingQuota = apiKey.DailyQuota - keyCounters.requestsToday
+	}
+	return *apiKey, keyUsage, nil
+}
+
+// hashAPIKey retorna el hash SHA-256 (hexadecimal) de una clave en claro.
+func hashAPIKey(plainKey string) string {
+	keyHash := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(keyHash[:])
+}
+
+// randomHex retorna 'byteCount' bytes aleatorios codificados en hexadecimal.
+func randomHex(byteCount int) (string, error) {
+	randomBytes := make([]byte, byteCount)
+	if _, readErr := rand.Read(randomBytes); readErr != nil {
+		return "", fmt.Errorf("error al generar bytes aleatorios: %w", readErr)
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
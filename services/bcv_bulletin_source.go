@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"precio-bcv-go/utils"
+
+	"github.com/extrame/xls"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// bulletinDatePattern localiza una fecha "dd/mm/aaaa" en el encabezado "Fecha Valor" de un boletín.
+var bulletinDatePattern = regexp.MustCompile(`(\d{1,2})/(\d{1,2})/(\d{4})`)
+
+// bulletinMaxColumns limita cuántas columnas se leen por fila de un boletín XLS.
+const bulletinMaxColumns = 32
+
+// BCVBulletinSource descarga el boletín "tipo de cambio" (.xls o .xlsx) del BCV y toma la publicación más reciente.
+// El boletín trae una hoja por día hábil, cada una con su "Fecha Valor" y una fila por moneda.
+type BCVBulletinSource struct {
+	bulletinURL string
+	client      *http.Client
+}
+
+// NewBCVBulletinSource crea la fuente que descarga el boletín de la URL indicada.
+func NewBCVBulletinSource(bulletinURL string) *BCVBulletinSource {
+	return &BCVBulletinSource{
+		bulletinURL: bulletinURL,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+			// El sitio del BCV suele presentar certificados no válidos; igual que en el scrapeo de la página.
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+// Name retorna el nombre de la fuente.
+func (source *BCVBulletinSource) Name() string {
+	return "bcv_xls"
+}
+
+// FetchRates descarga el boletín y retorna la publicación con la fecha valor más reciente.
+func (source *BCVBulletinSource) FetchRates(fetchContext context.Context) (RatePublication, error) {
+	bulletinRequest, requestErr := http.NewRequestWithContext(fetchContext, http.MethodGet, source.bulletinURL, nil)
+	if requestErr != nil {
+		return RatePublication{}, fmt.Errorf("error al crear la solicitud del boletín %s: %w", source.bulletinURL, requestErr)
+	}
+	resp, getErr := source.client.Do(bulletinRequest)
+	if getErr != nil {
+		return RatePublication{}, fmt.Errorf("error al descargar el boletín %s: %w", source.bulletinURL, getErr)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return RatePublication{}, fmt.Errorf("error al descargar el boletín %s: código de estado %d", source.bulletinURL, resp.StatusCode)
+	}
+	bulletinContent, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return RatePublication{}, fmt.Errorf("error al leer el boletín %s: %w", source.bulletinURL, readErr)
+	}
+
+	publications, parseErr := ParseBCVBulletin(bulletinContent)
+	if parseErr != nil {
+		return RatePublication{}, parseErr
+	}
+	latestPublication := publications[0]
+	for _, publication := range publications[1:] {
+		if publication.EffectiveDate.After(latestPublication.EffectiveDate) {
+			latestPublication = publication
+		}
+	}
+	slog.InfoContext(fetchContext, "Boletín leído", "publications", len(publications), "latest_effective_date", latestPublication.EffectiveDate.Format("2006-01-02"))
+	return latestPublication, nil
+}
+
+// xlsxSignature son los primeros bytes de un archivo .xlsx (un ZIP); los .xls usan el formato OLE2.
+var xlsxSignature = []byte("PK\x03\x04")
+
+// bulletinSheet es una hoja de un boletín, con el texto de las celdas de cada fila.
+type bulletinSheet struct {
+	name string
+	rows [][]string
+}
+
+// ParseBCVBulletin interpreta un boletín "tipo de cambio" del BCV, en formato .xls o .xlsx, y retorna
+// una publicación por cada hoja que tenga Fecha Valor y al menos la tasa del dólar. Las hojas sin esos datos se omiten.
+func ParseBCVBulletin(bulletinContent []byte) ([]RatePublication, error) {
+	var sheets []bulletinSheet
+	var readErr error
+	if bytes.HasPrefix(bulletinContent, xlsxSignature) {
+		sheets, readErr = readXLSXSheets(bulletinContent)
+	} else {
+		sheets, readErr = readXLSSheets(bulletinContent)
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	publications := []RatePublication{}
+	for _, sheet := range sheets {
+		publication, parsed := parseBulletinSheet(sheet.name, sheet.rows)
+		if !parsed {
+			slog.Warn("La hoja del boletín no tiene Fecha Valor o tasa del dólar. Se omite.", "sheet", sheet.name)
+			continue
+		}
+		publications = append(publications, publication)
+	}
+	if len(publications) == 0 {
+		return nil, fmt.Errorf("el boletín no contiene ninguna hoja de tasas reconocible")
+	}
+	return publications, nil
+}
+
+// readXLSSheets lee todas las hojas de un archivo .xls.
+func readXLSSheets(bulletinContent []byte) ([]bulletinSheet, error) {
+	workbook, openErr := xls.OpenReader(bytes.NewReader(bulletinContent), "utf-8")
+	if openErr != nil {
+		return nil, fmt.Errorf("error al abrir el boletín XLS: %w", openErr)
+	}
+	sheets := make([]bulletinSheet, 0, workbook.NumSheets())
+	for sheetIndex := 0; sheetIndex < workbook.NumSheets(); sheetIndex++ {
+		if worksheet := workbook.GetSheet(sheetIndex); worksheet != nil {
+			sheets = append(sheets, bulletinSheet{name: worksheet.Name, rows: xlsSheetRows(worksheet)})
+		}
+	}
+	return sheets, nil
+}
+
+// readXLSXSheets lee todas las hojas de un archivo .xlsx. Se leen los valores sin formato para no perder
+// los decimales que el formato de la celda oculta.
+func readXLSXSheets(bulletinContent []byte) ([]bulletinSheet, error) {
+	workbook, openErr := excelize.OpenReader(bytes.NewReader(bulletinContent))
+	if openErr != nil {
+		return nil, fmt.Errorf("error al abrir el boletín XLSX: %w", openErr)
+	}
+	defer workbook.Close()
+
+	sheetNames := workbook.GetSheetList()
+	sheets := make([]bulletinSheet, 0, len(sheetNames))
+	for _, sheetName := range sheetNames {
+		sheetRows, rowsErr := workbook.GetRows(sheetName, excelize.Options{RawCellValue: true})
+		if rowsErr != nil {
+			return nil, fmt.Errorf("error al leer la hoja '%s' del boletín XLSX: %w", sheetName, rowsErr)
+		}
+		sheets = append(sheets, bulletinSheet{name: sheetName, rows: sheetRows})
+	}
+	return sheets, nil
+}
+
+// parseBulletinSheet extrae la Fecha Valor y las tasas de las monedas soportadas de una hoja del boletín.
+// Una fila de moneda tiene una celda con el código ISO (ej. "USD"); su tasa en bolívares es el último
+// número de la fila. Si no hay encabezado "Fecha Valor", se intenta con el nombre de la hoja (ddmmaaaa).
+func parseBulletinSheet(sheetName string, sheetRows [][]string) (RatePublication, bool) {
+	publication := RatePublication{Rates: map[string]decimal.Decimal{}}
+
+	for _, rowCells := range sheetRows {
+		rowText := strings.Join(rowCells, " ")
+		if publication.EffectiveDate.IsZero() && strings.Contains(strings.ToLower(rowText), "fecha valor") {
+			publication.EffectiveDate = parseBulletinDate(rowText)
+			continue
+		}
+
+		rowCurrency := ""
+		for _, cellText := range rowCells {
+			if _, supported := SupportedCurrencies[strings.ToUpper(strings.TrimSpace(cellText))]; supported {
+				rowCurrency = strings.ToUpper(strings.TrimSpace(cellText))
+				break
+			}
+		}
+		if rowCurrency == "" {
+			continue
+		}
+		for cellIndex := len(rowCells) - 1; cellIndex >= 0; cellIndex-- {
+			if cellRate, parseErr := parseBulletinNumber(rowCells[cellIndex]); parseErr == nil && cellRate.IsPositive() {
+				publication.Rates[rowCurrency] = cellRate
+				break
+			}
+		}
+	}
+
+	if publication.EffectiveDate.IsZero() {
+		if sheetDate, parseErr := time.ParseInLocation("02012006", strings.TrimSpace(sheetName), time.Local); parseErr == nil {
+			publication.EffectiveDate = sheetDate
+		}
+	}
+	return publication, !publication.EffectiveDate.IsZero() && publication.Rates[DefaultCurrency].IsPositive()
+}
+
+// parseBulletinDate obtiene la fecha "dd/mm/aaaa" de un texto, o una fecha RFC 3339 de una celda con formato de fecha.
+// Retorna cero si no encuentra ninguna.
+func parseBulletinDate(rowText string) time.Time {
+	if dateMatch := bulletinDatePattern.FindStringSubmatch(rowText); dateMatch != nil {
+		dayNumber, _ := strconv.Atoi(dateMatch[1])
+		monthNumber, _ := strconv.Atoi(dateMatch[2])
+		yearNumber, _ := strconv.Atoi(dateMatch[3])
+		return time.Date(yearNumber, time.Month(monthNumber), dayNumber, 0, 0, 0, 0, time.Local)
+	}
+	for _, rowField := range strings.Fields(rowText) {
+		if cellDate, parseErr := time.Parse(time.RFC3339, rowField); parseErr == nil {
+			return time.Date(cellDate.Year(), cellDate.Month(), cellDate.Day(), 0, 0, 0, 0, time.Local)
+		}
+	}
+	return time.Time{}
+}
+
+// parseBulletinNumber convierte una celda numérica. Las celdas numéricas del XLS llegan con punto decimal
+// y, al ser valores binarios de la hoja, pueden traer ruido tras el octavo decimal (ej. "36.123456780000001"),
+// por lo que se redondean a los decimales que publica el BCV; las de texto pueden venir en formato venezolano.
+func parseBulletinNumber(cellText string) (decimal.Decimal, error) {
+	if parsedNumber, parseErr := decimal.NewFromString(strings.TrimSpace(cellText)); parseErr == nil {
+		return utils.Round(parsedNumber, utils.RatePlaces, utils.RoundHalfEven), nil
+	}
+	return ParseVenezuelanNumber(cellText)
+}
+
+// xlsSheetRows retorna el texto de las celdas de cada fila de la hoja. Las filas vacías quedan como nil.
+func xlsSheetRows(worksheet *xls.WorkSheet) [][]string {
+	sheetRows := make([][]string, 0, int(worksheet.MaxRow)+1)
+	for rowIndex := 0; rowIndex <= int(worksheet.MaxRow); rowIndex++ {
+		sheetRow := xlsRow(worksheet, rowIndex)
+		if sheetRow == nil {
+			sheetRows = append(sheetRows, nil)
+			continue
+		}
+		lastColumn := sheetRow.LastCol()
+		if lastColumn < bulletinMaxColumns {
+			lastColumn = bulletinMaxColumns // LastCol no siempre es confiable en filas sin registro ROW.
+		}
+		rowCells := make([]string, 0, lastColumn)
+		for columnIndex := 0; columnIndex < lastColumn; columnIndex++ {
+			rowCells = append(rowCells, sheetRow.Col(columnIndex))
+		}
+		sheetRows = append(sheetRows, rowCells)
+	}
+	return sheetRows
+}
+
+// xlsRow retorna la fila indicada, o nil si la hoja no la tiene: la librería entra en pánico
+// al pedir una fila inexistente, por lo que se recupera aquí.
+func xlsRow(worksheet *xls.WorkSheet, rowIndex int) (sheetRow *xls.Row) {
+	defer func() {
+		if recover() != nil {
+			sheetRow = nil
+		}
+	}()
+	return worksheet.Row(rowIndex)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// bulletinSheetRows son las celdas de una hoja de boletín de prueba, por referencia (ej. "B10").
+type bulletinSheetRows map[string]any
+
+// newBulletinXLSX arma un boletín .xlsx con una hoja por cada entrada de 'sheets' (nombre ddmmaaaa → celdas).
+func newBulletinXLSX(t *testing.T, sheetNames []string, sheets map[string]bulletinSheetRows) []byte {
+	t.Helper()
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+	for sheetIndex, sheetName := range sheetNames {
+		if sheetIndex == 0 {
+			workbook.SetSheetName("Sheet1", sheetName)
+		} else if _, sheetErr := workbook.NewSheet(sheetName); sheetErr != nil {
+			t.Fatal(sheetErr)
+		}
+		for cellReference, cellValue := range sheets[sheetName] {
+			if setErr := workbook.SetCellValue(sheetName, cellReference, cellValue); setErr != nil {
+				t.Fatal(setErr)
+			}
+		}
+	}
+	bulletinBuffer, writeErr := workbook.WriteToBuffer()
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	return bulletinBuffer.Bytes()
+}
+
+func TestBCVBulletinSourceFetchRates(t *testing.T) {
+	bulletinContent := newBulletinXLSX(t, []string{"18032024", "15032024"}, map[string]bulletinSheetRows{
+		"18032024": {
+			"B5":  "Fecha Valor: 18/03/2024",
+			"B10": "USD", "F10": 36.05, "G10": 36.12345678,
+			"B11": "EUR", "G11": "39,26457118", // Celda de texto en formato venezolano.
+			"B12": "GBP", "G12": 45.1, // Moneda no soportada.
+		},
+		"15032024": {
+			"B5":  "Fecha Valor: 15/03/2024",
+			"B10": "USD", "G10": 36.01,
+		},
+	})
+	bulletinServer := newStaticServer(t, http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", bulletinContent)
+
+	publication, fetchErr := NewBCVBulletinSource(bulletinServer.URL).FetchRates(context.Background())
+	if fetchErr != nil {
+		t.Fatalf("FetchRates retornó un error: %v", fetchErr)
+	}
+	// Se toma la hoja con la fecha valor más reciente y, en cada fila, el último número (la tasa de venta).
+	if publication.EffectiveDate.Format("2006-01-02") != "2024-03-18" {
+		t.Fatalf("fecha valor = %s; se esperaba 2024-03-18", publication.EffectiveDate)
+	}
+	expectedRates := map[string]string{"USD": "36.12345678", "EUR": "39.26457118"}
+	if len(publication.Rates) != len(expectedRates) {
+		t.Fatalf("tasas = %v; se esperaba %v", publication.Rates, expectedRates)
+	}
+	for currency, expectedRate := range expectedRates {
+		if publication.Rates[currency].String() != expectedRate {
+			t.Fatalf("tasa de %s = %s; se esperaba %s", currency, publication.Rates[currency], expectedRate)
+		}
+	}
+}
+
+func TestBCVBulletinSourceErrors(t *testing.T) {
+	sheetWithoutRates := newBulletinXLSX(t, []string{"Hoja"}, map[string]bulletinSheetRows{
+		"Hoja": {"B2": "Boletín en mantenimiento"},
+	})
+	testCases := []struct {
+		name            string
+		statusCode      int
+		bulletinContent []byte
+	}{
+		{name: "código de estado de error", statusCode: http.StatusNotFound, bulletinContent: []byte("not found")},
+		{name: "cuerpo que no es un boletín", statusCode: http.StatusOK, bulletinContent: []byte("<html>mantenimiento</html>")},
+		{name: "xlsx corrupto", statusCode: http.StatusOK, bulletinContent: append([]byte("PK\x03\x04"), []byte("truncado")...)},
+		{name: "hoja sin tasas", statusCode: http.StatusOK, bulletinContent: sheetWithoutRates},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bulletinServer := newStaticServer(t, testCase.statusCode, "application/vnd.ms-excel", testCase.bulletinContent)
+			if _, fetchErr := NewBCVBulletinSource(bulletinServer.URL).FetchRates(context.Background()); fetchErr == nil {
+				t.Fatal("FetchRates no retornó un error")
+			}
+		})
+	}
+}
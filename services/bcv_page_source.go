@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/shopspring/decimal"
+)
+
+// rateElementSelector localiza, dentro del bloque de cada moneda, el elemento que contiene solo la tasa.
+const rateElementSelector = "strong"
+
+// BCVPageSource scrapea las tasas publicadas en el recuadro de la página principal del BCV.
+type BCVPageSource struct {
+	pageURL string
+}
+
+// NewBCVPageSource crea la fuente que scrapea la página indicada (normalmente https://www.bcv.org.ve/).
+func NewBCVPageSource(pageURL string) *BCVPageSource {
+	return &BCVPageSource{pageURL: pageURL}
+}
+
+// Name retorna el nombre de la fuente.
+func (source *BCVPageSource) Name() string {
+	return "bcv_page"
+}
+
+// FetchRates scrapea las tasas de todas las monedas publicadas en la página del BCV.
+// Las monedas que fallen se omiten, y la "Fecha Valor" queda en cero si no se pudo leer.
+func (source *BCVPageSource) FetchRates(fetchContext context.Context) (RatePublication, error) {
+	collyCollector := colly.NewCollector()
+	scrapedRates := map[string]decimal.Decimal{}
+	var scrapedEffectiveDate time.Time
+
+	// Configurar Colly para ignorar certificados TLS no válidos.
+	// NOTA: 'InsecureSkipVerify: true' es SOLO para desarrollo/entornos específicos.
+	// No se recomienda en producción por razones de seguridad.
+	collyCollector.WithTransport(&http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	})
+
+	// Define la lógica a ejecutar cuando Colly encuentra el elemento HTML de cada moneda (ej. "#dolar").
+	for currencyCode, elementID := range SupportedCurrencies {
+		currency := currencyCode
+		collyCollector.OnHTML("#"+elementID, func(element *colly.HTMLElement) {
+			// Solo se lee el elemento de la tasa: el resto del bloque puede tener otros números (fechas, notas).
+			parsedRate, extractErr := ExtractRate(element.ChildText(rateElementSelector))
+			if extractErr != nil {
+				slog.WarnContext(fetchContext, "Error al extraer la tasa scrapeada. No se pudo obtener un valor válido.", "currency", currency, "error", extractErr)
+				return // Salir del handler OnHTML si no hay un valor válido.
+			}
+
+			slog.DebugContext(fetchContext, "Valor scrapeado", "currency", currency, "rate", parsedRate)
+			scrapedRates[currency] = parsedRate
+		})
+	}
+
+	// La "Fecha Valor" se publica en el atributo "content" (ISO 8601) del span de fecha del recuadro de tasas.
+	collyCollector.OnHTML(".dinpro .date-display-single", func(element *colly.HTMLElement) {
+		if !scrapedEffectiveDate.IsZero() {
+			return // Solo interesa la primera fecha del recuadro.
+		}
+		parsedDate, parseErr := time.Parse(time.RFC3339, strings.TrimSpace(element.Attr("content")))
+		if parseErr != nil {
+			slog.WarnContext(fetchContext, "No se pudo interpretar la Fecha Valor", "content", element.Attr("content"), "error", parseErr)
+			return
+		}
+		scrapedEffectiveDate = parsedDate
+		slog.DebugContext(fetchContext, "Fecha Valor scrapeada", "effective_date", parsedDate.Format("2006-01-02"))
+	})
+
+	// Visitar la URL del BCV para iniciar el proceso de scrapeo.
+	if visitErr := collyCollector.Visit(source.pageURL); visitErr != nil {
+		return RatePublication{}, fmt.Errorf("error al visitar %s: %w", source.pageURL, visitErr)
+	}
+
+	return RatePublication{Rates: scrapedRates, EffectiveDate: scrapedEffectiveDate}, nil
+}
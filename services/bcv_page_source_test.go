@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// updateGolden regenera los archivos .golden de testdata: go test ./services -run Golden -update
+var updateGolden = flag.Bool("update", false, "regenera los archivos .golden de testdata")
+
+// formatPublication describe una publicación en texto estable (monedas ordenadas), para compararla con un archivo .golden.
+func formatPublication(publication RatePublication) string {
+	var publicationBuilder strings.Builder
+	effectiveDate := "none"
+	if !publication.EffectiveDate.IsZero() {
+		effectiveDate = publication.EffectiveDate.Format("2006-01-02")
+	}
+	fmt.Fprintf(&publicationBuilder, "effective_date=%s\n", effectiveDate)
+
+	currencies := make([]string, 0, len(publication.Rates))
+	for currency := range publication.Rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		fmt.Fprintf(&publicationBuilder, "%s=%s\n", currency, publication.Rates[currency])
+	}
+	return publicationBuilder.String()
+}
+
+// TestBCVPageSourceGolden scrapea cada página guardada en testdata/bcv_page y compara las tasas y la
+// Fecha Valor obtenidas con su archivo .golden.
+func TestBCVPageSourceGolden(t *testing.T) {
+	snapshotPaths, globErr := filepath.Glob(filepath.Join("testdata", "bcv_page", "*.html"))
+	if globErr != nil || len(snapshotPaths) == 0 {
+		t.Fatalf("no hay páginas guardadas en testdata/bcv_page: %v", globErr)
+	}
+
+	for _, snapshotPath := range snapshotPaths {
+		t.Run(filepath.Base(snapshotPath), func(t *testing.T) {
+			pageContent, readErr := os.ReadFile(snapshotPath)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			pageServer := httptest.NewServer(http.HandlerFunc(func(httpResponseWriter http.ResponseWriter, _ *http.Request) {
+				httpResponseWriter.Header().Set("Content-Type", "text/html; charset=utf-8")
+				httpResponseWriter.Write(pageContent)
+			}))
+			defer pageServer.Close()
+
+			publication, fetchErr := NewBCVPageSource(pageServer.URL).FetchRates(context.Background())
+			if fetchErr != nil {
+				t.Fatalf("FetchRates retornó un error: %v", fetchErr)
+			}
+			scrapedText := formatPublication(publication)
+
+			goldenPath := strings.TrimSuffix(snapshotPath, ".html") + ".golden"
+			if *updateGolden {
+				if writeErr := os.WriteFile(goldenPath, []byte(scrapedText), 0o644); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+			}
+			expectedText, readGoldenErr := os.ReadFile(goldenPath)
+			if readGoldenErr != nil {
+				t.Fatalf("no se pudo leer %s (use -update para crearlo): %v", goldenPath, readGoldenErr)
+			}
+			if scrapedText != string(expectedText) {
+				t.Fatalf("tasas scrapeadas de %s:\n%s\nse esperaba:\n%s", snapshotPath, scrapedText, expectedText)
+			}
+		})
+	}
+}
+
+func TestBCVPageSourceErrors(t *testing.T) {
+	t.Run("código de estado de error", func(t *testing.T) {
+		pageServer := newStaticServer(t, http.StatusServiceUnavailable, "text/html", []byte("<html>mantenimiento</html>"))
+		if _, fetchErr := NewBCVPageSource(pageServer.URL).FetchRates(context.Background()); fetchErr == nil {
+			t.Fatal("FetchRates no retornó un error")
+		}
+	})
+
+	// Una página sin el recuadro de tasas no es un error de la fuente, pero no publica ninguna tasa;
+	// fetchFromSources la descarta por no incluir la del dólar.
+	t.Run("página sin tasas", func(t *testing.T) {
+		pageServer := newStaticServer(t, http.StatusOK, "text/html", []byte("<html><body><div id=\"dolar\">Sin datos"))
+		publication, fetchErr := NewBCVPageSource(pageServer.URL).FetchRates(context.Background())
+		if fetchErr != nil {
+			t.Fatalf("FetchRates retornó un error: %v", fetchErr)
+		}
+		if len(publication.Rates) != 0 || !publication.EffectiveDate.IsZero() {
+			t.Fatalf("publicación = %+v; se esperaba vacía", publication)
+		}
+	})
+}
@@ -1,155 +1,534 @@
 package services
 
 import (
-	"crypto/tls"
-	"log"
-	"net/http"
-	"strconv"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/gocolly/colly/v2"
+	"precio-bcv-go/config"
+	"precio-bcv-go/logging"
+	"precio-bcv-go/metrics"
+	"precio-bcv-go/models"
+	"precio-bcv-go/tracing"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultCurrency es la moneda usada cuando una petición no especifica ninguna.
+const DefaultCurrency = "USD"
+
+// BolivarCurrency es el código del bolívar, la moneda en la que el BCV expresa todas sus tasas.
+const BolivarCurrency = "VES"
+
+// Orígenes de las tasas actuales, informados a los clientes en el campo "source" de las respuestas.
+const (
+	RateSourceScrape     = "scrape"      // Obtenidas de una fuente en la última actualización.
+	RateSourceDBToday    = "db-today"    // Ya guardadas hoy en la base de datos.
+	RateSourceDBFallback = "db-fallback" // Último valor conocido, al no poder obtener las del día.
+	RateSourceManual     = "manual"      // Cargadas por un administrador; es también el "source" de esos registros en la DB.
 )
 
+// SupportedCurrencies relaciona el código ISO de cada moneda publicada por el BCV
+// con el ID del elemento HTML que la contiene en la página principal del BCV.
+var SupportedCurrencies = map[string]string{
+	"USD": "dolar",
+	"EUR": "euro",
+	"CNY": "yuan",
+	"TRY": "lira",
+	"RUB": "rublo",
+}
+
 // BCVService maneja la lógica para obtener, almacenar y proporcionar el valor actual del BCV.
 type BCVService struct {
-	bcvValueMutex sync.Mutex 
-	currentBCV    float64   
-	dbService     *MongoDBService 
-	whatsAppService *WhatsAppService
+	// Tasas actuales: se leen sin bloqueo y solo se reemplazan completas (ver publishRates).
+	currentSnapshot      atomic.Pointer[RateSnapshot]
+	lastSuccessfulScrape atomic.Pointer[time.Time] // nil si aún no hubo un scrapeo exitoso.
+	maxRateAge           time.Duration             // Antigüedad de la fecha valor a partir de la cual las tasas se informan como desactualizadas.
+	dbService            RateRepository
+	notifier             Notifier
+	// Avisos de cambio de tasa a suscriptores y umbral de "movimiento grande".
+	rateSubscriberNotifier    Notifier
+	largeMoveThresholdPercent float64
+
+	// Fuentes de tasas en orden de prioridad y tolerancia (en %) para marcar desacuerdos entre ellas.
+	rateSources            []RateSource
+	sourceTolerancePercent float64
+
+	// Tasas nuevas que superan la variación diaria máxima quedan en cuarentena hasta que un administrador las revise.
+	quarantineRepository  QuarantineRepository
+	maxDailyChangePercent float64
+
+	// Reintentos dentro de un scrapeo (backoff exponencial con jitter).
+	maxScrapeAttempts int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+
+	// Reintentos programados cuando una actualización no logra guardar una tasa fresca.
+	updateMutex      sync.Mutex
+	followUpMutex    sync.Mutex
+	followUpTimer    *time.Timer
+	followUpAttempts int
+	retryInterval    time.Duration
+	maxFollowUps     int
+	stopped          bool          // Protegido por updateMutex; tras Stop ya no se actualizan las tasas.
+	stopRequested    chan struct{} // Se cierra al llamar a Stop, para cortar las esperas entre reintentos.
+	stopOnce         sync.Once
 }
 
 // NewBCVService crea e inicializa una nueva instancia de BCVService.
-func NewBCVService(mongoDBService *MongoDBService, whatsappAppService *WhatsAppService) *BCVService {
-	return &BCVService{
-		currentBCV: 0.0, // Inicializa el valor actual del BCV a 0.0; será actualizado en la primera llamada a UpdateBCV.
-		dbService:  mongoDBService,
- 		whatsAppService: whatsappAppService,
+// 'rateRepository' puede ser cualquier backend de almacenamiento (MongoDB, memoria o bbolt).
+// 'alertNotifier' recibe las alertas operativas y 'rateSubscriberNotifier' los avisos de nuevas tasas;
+// normalmente ambos son un NotificationDispatcher. 'rateSources' se consultan en orden de prioridad.
+// 'quarantineRepository' guarda las tasas sospechosas pendientes de revisión.
+func NewBCVService(appConfig *config.Config, rateRepository RateRepository, alertNotifier Notifier, rateSubscriberNotifier Notifier, rateSources []RateSource, quarantineRepository QuarantineRepository) *BCVService {
+	bcvService := &BCVService{
+		dbService:                 rateRepository,
+		notifier:                  alertNotifier,
+		rateSubscriberNotifier:    rateSubscriberNotifier,
+		largeMoveThresholdPercent: appConfig.LargeMoveThresholdPercent,
+		rateSources:               rateSources,
+		sourceTolerancePercent:    appConfig.RateSourceTolerancePercent,
+		quarantineRepository:      quarantineRepository,
+		maxDailyChangePercent:     appConfig.MaxDailyChangePercent,
+		maxRateAge:                appConfig.ReadinessMaxRateAge,
+		maxScrapeAttempts:         appConfig.ScrapeMaxAttempts,
+		initialBackoff:            appConfig.ScrapeInitialBackoff,
+		maxBackoff:                appConfig.ScrapeMaxBackoff,
+		retryInterval:             appConfig.ScrapeRetryInterval,
+		maxFollowUps:              appConfig.ScrapeMaxFollowUps,
+		stopRequested:             make(chan struct{}),
+	}
+	// Sin tasas hasta la primera llamada a UpdateBCV.
+	bcvService.currentSnapshot.Store(newRateSnapshot(nil, time.Time{}, time.Time{}, "", false, bcvService.maxRateAge))
+	return bcvService
+}
+
+// CurrentRates retorna el snapshot de las tasas actuales. Nunca es nil: antes de la primera actualización
+// es un snapshot vacío. Leerlo no toma ningún bloqueo, y el snapshot no cambia aunque luego se publiquen tasas nuevas.
+func (service *BCVService) CurrentRates() *RateSnapshot {
+	return service.currentSnapshot.Load()
+}
+
+// GetBCV obtiene el valor actual del dólar de forma segura para concurrencia.
+func (service *BCVService) GetBCV() decimal.Decimal {
+	return service.CurrentRates().Value
+}
+
+// GetRate obtiene el valor actual de la moneda indicada (código ISO, ej. "EUR").
+// Retorna 0 si no hay una tasa disponible para esa moneda.
+func (service *BCVService) GetRate(currency string) decimal.Decimal {
+	return service.CurrentRates().Rate(currency)
+}
+
+// LastSuccessfulScrape retorna la última vez que una fuente publicó la tasa del dólar; cero si aún no ocurrió.
+func (service *BCVService) LastSuccessfulScrape() time.Time {
+	if lastScrape := service.lastSuccessfulScrape.Load(); lastScrape != nil {
+		return *lastScrape
+	}
+	return time.Time{}
+}
+
+// publishRates reemplaza el snapshot de las tasas actuales y retorna el anterior.
+// Debe llamarse con updateMutex tomado, para que las actualizaciones no se pisen entre sí.
+func (service *BCVService) publishRates(rateSnapshot *RateSnapshot) *RateSnapshot {
+	previousSnapshot := service.currentSnapshot.Swap(rateSnapshot)
+	metrics.SetCurrentRates(rateSnapshot.Rates)
+	return previousSnapshot
+}
+
+// PingStorage verifica que el almacenamiento de tasas responda.
+func (service *BCVService) PingStorage(requestContext context.Context) error {
+	return service.dbService.Ping(requestContext)
+}
+
+// GetRateHistory retorna la serie de tasas guardadas entre dos instantes, paginada. El rango se aplica al
+// timestamp en que se obtuvo cada registro, no a su fecha valor (para esa, ver GetRateInEffectOn).
+func (service *BCVService) GetRateHistory(requestContext context.Context, fromTimestamp, toTimestamp time.Time, pageNumber, pageSize int) ([]models.BCVRate, int64, error) {
+	return service.dbService.GetBCVRateHistory(requestContext, fromTimestamp, toTimestamp, pageNumber, pageSize)
+}
+
+// GetRateInEffectOn retorna el registro de tasas legalmente vigente en el instante indicado,
+// según la "Fecha Valor" publicada por el BCV. Retorna nil si no hay ningún registro vigente.
+func (service *BCVService) GetRateInEffectOn(requestContext context.Context, effectiveTimestamp time.Time) (*models.BCVRate, error) {
+	return service.dbService.GetBCVRateInEffectOn(requestContext, effectiveTimestamp)
+}
+
+// UpdateBCV actualiza las tasas internas del BCV. Es la tarea diaria programada por el cron.
+// Si no se logra guardar una tasa fresca, programa reintentos cada 'retryInterval'
+// hasta obtenerla o agotar 'maxFollowUps'. Cada ejecución tiene un "run_id" propio, compartido por sus
+// reintentos, que aparece en todas sus líneas de log (scrapeo, base de datos y alertas).
+func (service *BCVService) UpdateBCV() {
+	service.cancelFollowUps() // Una ejecución del cron reemplaza cualquier reintento pendiente.
+
+	runContext := logging.WithRunID(context.Background(), logging.NewID())
+	if !service.refreshRates(runContext, false) {
+		service.scheduleFollowUp(runContext)
+	}
+}
+
+// scheduleFollowUp programa el siguiente reintento de actualización, si no se agotó el límite.
+func (service *BCVService) scheduleFollowUp(runContext context.Context) {
+	service.followUpMutex.Lock()
+	defer service.followUpMutex.Unlock()
+
+	select {
+	case <-service.stopRequested:
+		return // El servicio se está cerrando.
+	default:
+	}
+	if service.retryInterval <= 0 || service.followUpAttempts >= service.maxFollowUps {
+		if service.maxFollowUps > 0 {
+			slog.WarnContext(runContext, "Se agotaron los reintentos programados sin obtener una tasa fresca. Se esperará al próximo cron.", "follow_ups", service.maxFollowUps)
+			service.sendAlertAsync(runContext, fmt.Sprintf("Alerta: Tras %d reintentos no se pudo obtener la tasa del BCV. Se mantiene el último valor conocido hasta la próxima ejecución programada.", service.maxFollowUps))
+		}
+		return
+	}
+
+	// Si un reintento y una ejecución del cron fallan a la vez, ambos llegan aquí; se detiene el reintento
+	// pendiente para que quede una sola cadena de reintentos.
+	if service.followUpTimer != nil {
+		service.followUpTimer.Stop()
+	}
+	service.followUpAttempts++
+	slog.InfoContext(runContext, "Reintento programado", "follow_up", service.followUpAttempts, "max_follow_ups", service.maxFollowUps, "retry_in", service.retryInterval.String())
+	service.followUpTimer = time.AfterFunc(service.retryInterval, func() { service.runFollowUp(runContext) })
+}
+
+// runFollowUp ejecuta un reintento programado y, si vuelve a fallar, programa el siguiente.
+func (service *BCVService) runFollowUp(runContext context.Context) {
+	if service.refreshRates(runContext, true) {
+		service.cancelFollowUps()
+		return
+	}
+	service.scheduleFollowUp(runContext)
+}
+
+// cancelFollowUps detiene el reintento pendiente y reinicia el contador de reintentos.
+func (service *BCVService) cancelFollowUps() {
+	service.followUpMutex.Lock()
+	defer service.followUpMutex.Unlock()
+
+	if service.followUpTimer != nil {
+		service.followUpTimer.Stop()
+		service.followUpTimer = nil
 	}
+	service.followUpAttempts = 0
 }
 
-// GetBCV obtiene el valor actual del BCV de forma segura para concurrencia.
-func (service *BCVService) GetBCV() float64 { 
-	service.bcvValueMutex.Lock()
-	defer service.bcvValueMutex.Unlock()
-	return service.currentBCV
+// Stop cancela los reintentos programados y espera a que termine la actualización en curso, si la hay,
+// para que el almacenamiento pueda cerrarse sin cortarla a la mitad. Una actualización que está esperando
+// entre intentos de scrapeo deja de esperar y termina con el último valor conocido. Las ejecuciones posteriores
+// de UpdateBCV no hacen nada. Retorna el error de 'shutdownContext' si vence antes de que la actualización termine.
+func (service *BCVService) Stop(shutdownContext context.Context) error {
+	service.stopOnce.Do(func() { close(service.stopRequested) })
+	service.cancelFollowUps()
+
+	updateFinished := make(chan struct{})
+	go func() {
+		service.updateMutex.Lock()
+		service.stopped = true
+		service.updateMutex.Unlock()
+		close(updateFinished)
+	}()
+
+	select {
+	case <-updateFinished:
+		slog.Info("Actualizaciones de BCV detenidas.")
+		return nil
+	case <-shutdownContext.Done():
+		return shutdownContext.Err()
+	}
 }
 
-// UpdateBCV actualiza el valor interno del BCV.
-// Primero intenta obtener el valor de la base de datos para el día actual.
-// Si no lo encuentra, realiza un scrapeo desde el BCV.
-// Si el scrapeo es exitoso, guarda el nuevo valor en la base de datos.
-// Si tanto la DB como el scrapeo fallan, intenta obtener el último valor conocido de la DB.
-func (service *BCVService) UpdateBCV() { 
-	log.Println("Iniciando actualización de BCV...")
+// refreshRates actualiza las tasas internas del BCV y retorna true si quedó guardada una tasa fresca del día.
+// Primero intenta obtener las tasas de la base de datos para el día actual.
+// Si no las encuentra, realiza un scrapeo desde el BCV (con reintentos y backoff).
+// Si el scrapeo es exitoso, guarda las nuevas tasas en la base de datos, salvo que su variación respecto
+// a la última tasa guardada supere la máxima diaria: en ese caso quedan en cuarentena.
+// Si tanto la DB como el scrapeo fallan, intenta obtener el último registro conocido de la DB.
+// En los reintentos programados ('isFollowUp') no se repite la alerta de fallo.
+func (service *BCVService) refreshRates(runContext context.Context, isFollowUp bool) bool {
+	// Evita que el cron y un reintento programado actualicen al mismo tiempo.
+	service.updateMutex.Lock()
+	defer service.updateMutex.Unlock()
+	if service.stopped {
+		return true // El servicio se está cerrando; no se actualiza ni se programan reintentos.
+	}
+
+	runContext, refreshSpan := tracing.Tracer().Start(runContext, "bcv.refresh_rates")
+	defer refreshSpan.End()
+
+	slog.InfoContext(runContext, "Iniciando actualización de BCV...", "follow_up", isFollowUp)
+	freshRateStored := false
+	updateOutcome := "failed" // Origen de la tasa resultante, para la métrica de actualizaciones.
 
 	// Intentar obtener el BCV para el día actual de la base de datos.
-	bcvTodayFromDB, dbQueryErr := service.dbService.GetBCVRateForToday() 
+	bcvTodayFromDB, dbQueryErr := service.dbService.GetBCVRateForToday(runContext)
 	if dbQueryErr != nil {
-		log.Printf("Advertencia: Error al obtener BCV del día de la base de datos: %v. Intentando scrapeo o último valor conocido...\n", dbQueryErr)
+		slog.WarnContext(runContext, "Error al obtener BCV del día de la base de datos. Intentando scrapeo o último valor conocido...", "error", dbQueryErr)
 	}
 
-	var fetchedBCVValue float64 
+	fetchedRates := map[string]decimal.Decimal{}
+	var fetchedRateDate, fetchedAt time.Time
+	fetchedSource := ""
+	usingFallback := false
 	// time.Local es importante para que la fecha coincida con la zona horaria del servidor.
-	currentDayTimestamp := time.Now().In(time.Local) 
-	log.Print("Dia Actual: ", currentDayTimestamp)
+	currentDayTimestamp := time.Now().In(time.Local)
+	slog.DebugContext(runContext, "Día actual", "now", currentDayTimestamp)
 
-	if bcvTodayFromDB > 0 {
-		// Si se encontró un valor para hoy en la DB, usar ese valor.
-		fetchedBCVValue = bcvTodayFromDB
-		log.Printf("BCV del día actual obtenido de la base de datos: %.4f\n", fetchedBCVValue)
+	if bcvTodayFromDB != nil && bcvTodayFromDB.RateFor(DefaultCurrency).IsPositive() {
+		// Si se encontró un registro para hoy en la DB, usar sus tasas.
+		fetchedRates = ratesFromRecord(bcvTodayFromDB.Rates, bcvTodayFromDB.RateFor(DefaultCurrency))
+		fetchedRateDate = recordEffectiveDate(*bcvTodayFromDB)
+		fetchedSource, fetchedAt = recordRateSource(*bcvTodayFromDB, RateSourceDBToday), bcvTodayFromDB.Timestamp
+		slog.InfoContext(runContext, "BCV del día actual obtenido de la base de datos", "bcv", fetchedRates[DefaultCurrency])
+		freshRateStored = true
+		updateOutcome = "db_today"
 	} else {
 		// Si no hay valor para hoy en la DB, proceder a scrapearlo.
-		log.Println("No se encontró BCV para el día actual en la base de datos. Scrapeando...")
-		scrapedBCV := service.fetchUSD() 
-
-		if scrapedBCV > 0 {
-			// Si el scrapeo fue exitoso, guardarlo en la DB con la fecha de hoy.
-			saveErr := service.dbService.SaveBCVRate(scrapedBCV, currentDayTimestamp)
-			if saveErr != nil {
-				log.Printf("Advertencia: Error al guardar el BCV scrapeado en MongoDB: %v\n", saveErr)
+		slog.InfoContext(runContext, "No se encontró BCV para el día actual en la base de datos. Scrapeando...")
+		scrapedPublication := service.fetchRatesWithRetry(runContext)
+		scrapedRates, scrapedEffectiveDate := scrapedPublication.Rates, scrapedPublication.EffectiveDate
+
+		if scrapedRates[DefaultCurrency].IsPositive() {
+			scrapeTime := time.Now()
+			service.lastSuccessfulScrape.Store(&scrapeTime)
+
+			// Si la página no publicó una "Fecha Valor" legible, la tasa se considera vigente desde hoy.
+			if scrapedEffectiveDate.IsZero() {
+				slog.WarnContext(runContext, "La fuente no indicó la Fecha Valor. Usando la fecha actual.", "source", scrapedPublication.Source)
+				scrapedEffectiveDate = time.Date(currentDayTimestamp.Year(), currentDayTimestamp.Month(), currentDayTimestamp.Day(), 0, 0, 0, 0, currentDayTimestamp.Location())
+			}
+
+			// Obtener la última tasa guardada antes de guardar la nueva, para avisar del cambio.
+			previousRecord, previousSearchErr := service.dbService.GetLatestBCVRate(runContext)
+			if previousSearchErr != nil {
+				slog.WarnContext(runContext, "Error al obtener la tasa anterior de la base de datos", "error", previousSearchErr)
+			}
+
+			scrapedPublication.EffectiveDate = scrapedEffectiveDate
+			if previousSearchErr != nil && service.maxDailyChangePercent > 0 {
+				// Sin la tasa anterior no se puede verificar la variación diaria. La tasa nueva no se publica
+				// ni se guarda, ya que podría ser un error de lectura; se mantienen las tasas actuales y,
+				// como no quedó una tasa fresca guardada, se programa un reintento.
+				slog.ErrorContext(runContext, "No se pudo verificar la variación diaria de la nueva tasa. Se mantienen las tasas actuales.", "bcv", scrapedRates[DefaultCurrency], "source", scrapedPublication.Source)
+				if !isFollowUp {
+					service.sendAlertAsync(runContext, fmt.Sprintf("Alerta: No se pudo leer la tasa anterior de la base de datos para verificar la nueva tasa del BCV (fecha valor %s, fuente '%s'). La nueva tasa no se publicó y se mantienen las tasas actuales. Error: %v",
+						scrapedEffectiveDate.Format("2006-01-02"), scrapedPublication.Source, previousSearchErr))
+				}
+				currentSnapshot := service.CurrentRates()
+				fetchedRates, fetchedRateDate = currentSnapshot.Rates, currentSnapshot.EffectiveDate
+				fetchedSource, fetchedAt = currentSnapshot.Source, currentSnapshot.FetchedAt
+				usingFallback = currentSnapshot.Fallback
+				updateOutcome = "unverified"
+			} else if violations := service.dailyChangeViolations(previousRecord, scrapedRates); len(violations) > 0 {
+				// Una variación fuera de lo normal suele ser un error de lectura (ej. 3.61 en lugar de 36.1).
+				// La tasa queda en cuarentena y se sigue usando la anterior hasta que un administrador la revise;
+				// no se programan reintentos, ya que volverían a leer el mismo valor.
+				service.quarantineRates(runContext, scrapedPublication, previousRecord, violations)
+				fetchedRates = ratesFromRecord(previousRecord.Rates, previousRecord.RateFor(DefaultCurrency))
+				fetchedRateDate = recordEffectiveDate(*previousRecord)
+				fetchedSource, fetchedAt = recordRateSource(*previousRecord, RateSourceDBFallback), previousRecord.Timestamp
+				usingFallback = true
+				freshRateStored = true
+				updateOutcome = "quarantined"
+			} else {
+				// Si el scrapeo fue exitoso, guardarlo en la DB con la fecha de hoy y su fecha valor.
+				saveErr := service.dbService.SaveBCVRate(runContext, models.BCVRate{
+					Rates:         scrapedRates,
+					EffectiveDate: scrapedEffectiveDate,
+					Timestamp:     currentDayTimestamp,
+					Source:        scrapedPublication.Source,
+				})
+				if saveErr != nil {
+					slog.WarnContext(runContext, "Error al guardar el BCV scrapeado en la base de datos", "error", saveErr)
+				} else {
+					freshRateStored = true
+				}
+				if previousRecord != nil {
+					service.notifyRateChange(runContext, ratesFromRecord(previousRecord.Rates, previousRecord.RateFor(DefaultCurrency)), scrapedRates, scrapedEffectiveDate)
+				}
+				fetchedRates = scrapedRates // Actualizar las tasas que se usarán.
+				fetchedRateDate = scrapedEffectiveDate
+				fetchedSource, fetchedAt = RateSourceScrape, currentDayTimestamp
+				updateOutcome = "scraped"
 			}
-			fetchedBCVValue = scrapedBCV // Actualizar el valor que se usará.
 		} else {
-			// Si el scrapeo falló (scrapedBCV es 0), intentar obtener el último valor conocido de la DB.
-			log.Println("Advertencia: El scrapeo de BCV falló (valor <= 0). Intentando obtener el último valor conocido de la base de datos...")
-
-			 // --- LLAMADA AL NUEVO SERVICIO DE WHATSAPP ---
-            alertMessage := "Alerta: El scrapeo del BCV falló y no se pudo obtener un valor válido. Verifique el sitio del BCV o la configuración de la aplicación."
-            // Ejecutar en goroutine para no bloquear y manejar el error de forma asíncrona.
-            go func(msg string) {
-                if sendErr := service.whatsAppService.SendAlert(msg); sendErr != nil {
-                    log.Printf("Error al enviar alerta de WhatsApp: %v\n", sendErr)
-                }
-            }(alertMessage)
-
-			lastKnownBCVFromDB, lastKnownDBSearchErr := service.dbService.GetLatestBCVRate() 
+			// Si el scrapeo falló (no hay tasa del dólar), intentar obtener el último valor conocido de la DB.
+			slog.WarnContext(runContext, "El scrapeo de BCV falló (valor <= 0). Intentando obtener el último valor conocido de la base de datos...")
+
+			// --- ALERTA POR TODOS LOS CANALES CONFIGURADOS ---
+			// Solo en la ejecución programada; los reintentos no repiten la alerta.
+			if !isFollowUp {
+				service.sendAlertAsync(runContext, "Alerta: El scrapeo del BCV falló y no se pudo obtener un valor válido. Verifique el sitio del BCV o la configuración de la aplicación.")
+			}
+
+			lastKnownBCVFromDB, lastKnownDBSearchErr := service.dbService.GetLatestBCVRate(runContext)
 			if lastKnownDBSearchErr != nil {
-				log.Printf("Error al obtener el último BCV conocido de la base de datos: %v\n", lastKnownDBSearchErr)
-				// fetchedBCVValue permanecerá en 0.0 si no hay ningún valor disponible.
-			} else if lastKnownBCVFromDB > 0 {
-				fetchedBCVValue = lastKnownBCVFromDB
-				log.Printf("Usando el último BCV conocido de la base de datos: %.4f\n", fetchedBCVValue)
+				slog.ErrorContext(runContext, "Error al obtener el último BCV conocido de la base de datos", "error", lastKnownDBSearchErr)
+				// fetchedRates permanecerá vacío si no hay ningún valor disponible.
+			} else if lastKnownBCVFromDB != nil && lastKnownBCVFromDB.RateFor(DefaultCurrency).IsPositive() {
+				fetchedRates = ratesFromRecord(lastKnownBCVFromDB.Rates, lastKnownBCVFromDB.RateFor(DefaultCurrency))
+				fetchedRateDate = recordEffectiveDate(*lastKnownBCVFromDB)
+				fetchedSource, fetchedAt = recordRateSource(*lastKnownBCVFromDB, RateSourceDBFallback), lastKnownBCVFromDB.Timestamp
+				usingFallback = true
+				slog.InfoContext(runContext, "Usando el último BCV conocido de la base de datos", "bcv", fetchedRates[DefaultCurrency])
+				updateOutcome = "db_fallback"
 			} else {
-				log.Println("No se pudo obtener el BCV ni por scrapeo ni de la base de datos. BCV se mantiene en 0.")
+				slog.ErrorContext(runContext, "No se pudo obtener el BCV ni por scrapeo ni de la base de datos. BCV se mantiene en 0.")
 			}
 		}
 	}
 
-	// Proteger la actualización de la variable interna con un mutex.
-	service.bcvValueMutex.Lock()
-	service.currentBCV = fetchedBCVValue // Actualiza la variable interna con el valor obtenido.
-	service.bcvValueMutex.Unlock()
+	// Publicar las tasas obtenidas como un snapshot nuevo; los lectores ven el anterior o este, nunca una mezcla.
+	service.publishRates(newRateSnapshot(fetchedRates, fetchedRateDate, fetchedAt, fetchedSource, usingFallback, service.maxRateAge))
+	metrics.RateUpdates.WithLabelValues(updateOutcome).Inc()
+	refreshSpan.SetAttributes(attribute.Bool("bcv.follow_up", isFollowUp), attribute.String("bcv.outcome", updateOutcome))
 
-	log.Printf("BCV interno actualizado a: %.4f\n", service.currentBCV)
+	slog.InfoContext(runContext, "BCV interno actualizado", "bcv", fetchedRates[DefaultCurrency], "currencies", len(fetchedRates), "outcome", updateOutcome)
+	return freshRateStored
 }
 
-// fetchUSD scrapea el valor del dólar de la página del BCV.
-// Retorna el valor scrapeado o 0.0 si ocurre un error o el valor no es válido.
-func (service *BCVService) fetchUSD() float64 { 
-	collyCollector := colly.NewCollector() 
-	var scrapedUSDValue float64 = 0.0  
+// sendAlertAsync envía una alerta por todos los canales configurados sin bloquear al llamador.
+// El envío conserva los identificadores de correlación de 'alertContext', pero no su cancelación,
+// para que la alerta salga aunque la petición o ejecución que la originó ya haya terminado.
+func (service *BCVService) sendAlertAsync(alertContext context.Context, alertMessage string) {
+	// Ejecutar en goroutine para no bloquear y manejar el error de forma asíncrona.
+	go func(sendContext context.Context, msg string) {
+		if sendErr := service.notifier.SendAlert(sendContext, NotificationAlert, msg); sendErr != nil {
+			slog.ErrorContext(sendContext, "Error al enviar alerta", "error", sendErr)
+		}
+	}(context.WithoutCancel(alertContext), alertMessage)
+}
 
-	// Configurar Colly para ignorar certificados TLS no válidos.
-	// NOTA: 'InsecureSkipVerify: true' es SOLO para desarrollo/entornos específicos.
-	// No se recomienda en producción por razones de seguridad.
-	collyCollector.WithTransport(&http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	})
+// fetchRatesWithRetry consulta las fuentes de tasas hasta 'maxScrapeAttempts' veces mientras ninguna publique la tasa del dólar.
+// Entre intentos espera un backoff exponencial (initialBackoff, 2x, 4x... hasta maxBackoff) con jitter,
+// para no insistir al mismo ritmo cuando el sitio del BCV está caído por unos minutos. La espera termina antes
+// si se cancela 'runContext' o se llama a Stop; en ese caso retorna la publicación del último intento.
+// Cuando una fuente responde, las fuentes de menor prioridad se usan para verificarla.
+func (service *BCVService) fetchRatesWithRetry(runContext context.Context) RatePublication {
+	for attemptNumber := 1; ; attemptNumber++ {
+		publication, sourceIndex, fetchErr := fetchFromSources(runContext, service.rateSources)
+		if fetchErr == nil {
+			service.crossCheckSources(runContext, publication, service.rateSources[sourceIndex+1:])
+			return publication
+		}
+		if attemptNumber >= service.maxScrapeAttempts {
+			slog.ErrorContext(runContext, "Error al obtener las tasas", "attempts", attemptNumber, "error", fetchErr)
+			return publication
+		}
 
-	// Define la lógica a ejecutar cuando Colly encuentra un elemento HTML con el ID "#dolar".
-	collyCollector.OnHTML("#dolar", func(element *colly.HTMLElement) {
-		// Extraer el texto del elemento y limpiarlo.
-		usdTextRaw := element.Text[101:109]
-		cleanedUSDText := strings.ReplaceAll(usdTextRaw, ",", ".") 
+		retryDelay := backoffWithJitter(attemptNumber, service.initialBackoff, service.maxBackoff)
+		slog.WarnContext(runContext, "Intento de scrapeo fallido. Reintentando...", "attempt", attemptNumber, "max_attempts", service.maxScrapeAttempts, "retry_in", retryDelay.String())
+		retryTimer := time.NewTimer(retryDelay)
+		select {
+		case <-retryTimer.C:
+		case <-service.stopRequested:
+			retryTimer.Stop()
+			slog.WarnContext(runContext, "Reintentos de scrapeo interrumpidos: el servicio se está cerrando.", "attempts", attemptNumber)
+			return publication
+		case <-runContext.Done():
+			retryTimer.Stop()
+			slog.WarnContext(runContext, "Reintentos de scrapeo interrumpidos", "attempts", attemptNumber, "error", runContext.Err())
+			return publication
+		}
+	}
+}
 
+// crossCheckSources compara la publicación elegida con la de cada fuente de respaldo y, si alguna tasa
+// difiere más que 'sourceTolerancePercent', lo registra y envía una alerta. La publicación elegida se
+// mantiene: el desacuerdo solo se marca para revisión manual.
+func (service *BCVService) crossCheckSources(runContext context.Context, primaryPublication RatePublication, fallbackSources []RateSource) {
+	allDisagreements := []string{}
+	for _, fallbackSource := range fallbackSources {
+		fallbackPublication, fetchErr := fetchRatesTraced(runContext, fallbackSource)
+		if fetchErr != nil {
+			slog.WarnContext(runContext, "No se pudo verificar con la fuente", "source", fallbackSource.Name(), "error", fetchErr)
+			continue
+		}
+		fallbackPublication.Source = fallbackSource.Name()
+		allDisagreements = append(allDisagreements, compareRatePublications(runContext, primaryPublication, fallbackPublication, service.sourceTolerancePercent)...)
+	}
+	if len(allDisagreements) == 0 {
+		return
+	}
 
-		// Convertir el texto limpio a un valor float.
-		parsedUSD, parseError := strconv.ParseFloat(cleanedUSDText, 64) 
-		log.Printf("Valor scrapeado de USD: %s\n", cleanedUSDText)
+	slog.WarnContext(runContext, "Las fuentes de tasas no coinciden", "tolerance_percent", service.sourceTolerancePercent, "disagreements", allDisagreements)
+	service.sendAlertAsync(runContext, fmt.Sprintf("Alerta: Las fuentes de tasas no coinciden (tolerancia %.2f%%). Se usa '%s'.\n%s",
+		service.sourceTolerancePercent, primaryPublication.Source, strings.Join(allDisagreements, "\n")))
+}
 
-		if parseError != nil {
-			log.Printf("Error al parsear float de BCV scrapeado '%s': %v. No se pudo obtener un valor válido.\n", cleanedUSDText, parseError)
-			scrapedUSDValue = 0.0 // Asegurar que sea 0.0 si hay un error de parseo.
-			return // Salir del handler OnHTML si hay un error de parseo.
-		}
+// backoffWithJitter calcula la espera antes del reintento número 'attemptNumber' (desde 1):
+// initialBackoff * 2^(attemptNumber-1), limitado a maxBackoff, y luego un valor aleatorio
+// entre la mitad y el total de esa espera ("equal jitter").
+func backoffWithJitter(attemptNumber int, initialBackoff, maxBackoff time.Duration) time.Duration {
+	baseDelay := initialBackoff
+	for step := 1; step < attemptNumber && baseDelay < maxBackoff; step++ {
+		baseDelay *= 2
+	}
+	if baseDelay > maxBackoff {
+		baseDelay = maxBackoff
+	}
+	if baseDelay <= 0 {
+		return 0
+	}
+	halfDelay := baseDelay / 2
+	return halfDelay + time.Duration(rand.Int64N(int64(baseDelay-halfDelay)+1))
+}
 
-		// Validar que el valor sea positivo.
-		if parsedUSD > 0 {
-			scrapedUSDValue = parsedUSD
-		} else {
-			log.Println("Advertencia: Valor del dólar scrapeado es <= 0. No se pudo obtener un valor válido.")
-			scrapedUSDValue = 0.0 // Asegurar que sea 0.0 si el valor no es positivo.
+// notifyRateChange avisa a los suscriptores cuando alguna tasa cambió respecto a la anterior,
+// indicando el valor anterior, el nuevo y la variación porcentual. Si la variación alcanza el umbral
+// de "movimiento grande", el mismo mensaje se envía también por los canales de alerta.
+func (service *BCVService) notifyRateChange(notifyContext context.Context, previousRates, newRates map[string]decimal.Decimal, effectiveDate time.Time) {
+	rateChanges := compareRates(previousRates, newRates)
+	if len(rateChanges) == 0 {
+		slog.InfoContext(notifyContext, "La tasa publicada no cambió respecto a la anterior. No se envían avisos.")
+		return
+	}
+
+	largeMove := isLargeMove(rateChanges, service.largeMoveThresholdPercent)
+	changeMessage := formatRateChangeMessage(rateChanges, effectiveDate, largeMove)
+	slog.InfoContext(notifyContext, "Cambio de tasa detectado. Enviando avisos...", "large_move", largeMove, "changes", len(rateChanges))
+
+	// Ejecutar en goroutine para no bloquear la actualización.
+	changeKind := NotificationRateChange
+	if largeMove {
+		changeKind = NotificationLargeMove
+	}
+	go func(sendContext context.Context, msg string) {
+		if sendErr := service.rateSubscriberNotifier.SendAlert(sendContext, changeKind, msg); sendErr != nil {
+			slog.ErrorContext(sendContext, "Error al enviar aviso de cambio de tasa", "error", sendErr)
 		}
-	})
+		if largeMove {
+			if sendErr := service.notifier.SendAlert(sendContext, NotificationLargeMove, msg); sendErr != nil {
+				slog.ErrorContext(sendContext, "Error al enviar alerta de movimiento grande", "error", sendErr)
+			}
+		}
+	}(context.WithoutCancel(notifyContext), changeMessage)
+}
 
-	// Visitar la URL del BCV para iniciar el proceso de scrapeo.
-	visitErr := collyCollector.Visit("https://www.bcv.org.ve/") 
-	if visitErr != nil {
-		log.Printf("Error al visitar BCV para scrapeo: %v. No se pudo obtener el valor.\n", visitErr)
-		return 0.0 // Retornar 0.0 para indicar que el scrapeo falló en la visita.
+// recordRateSource retorna el origen que se informa para tasas tomadas del registro 'record':
+// RateSourceManual si lo cargó un administrador, o 'storedSource' en otro caso.
+func recordRateSource(record models.BCVRate, storedSource string) string {
+	if record.Source == RateSourceManual {
+		return RateSourceManual
 	}
+	return storedSource
+}
 
-	return scrapedUSDValue
-}
\ No newline at end of file
+// ratesFromRecord copia las tasas de un registro de la DB, asegurando que el dólar esté presente
+// aun en documentos anteriores que solo guardaban el campo Value.
+func ratesFromRecord(recordRates map[string]decimal.Decimal, usdRate decimal.Decimal) map[string]decimal.Decimal {
+	copiedRates := make(map[string]decimal.Decimal, len(recordRates)+1)
+	for currency, currencyRate := range recordRates {
+		copiedRates[currency] = currencyRate
+	}
+	copiedRates[DefaultCurrency] = usdRate
+	return copiedRates
+}
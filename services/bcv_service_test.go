@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/tracing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRefreshRatesSpans(t *testing.T) {
+	spanExporter := tracetest.NewInMemoryExporter()
+	tracerProvider, installErr := tracing.Install(spanExporter, "precio-bcv-test", 1)
+	if installErr != nil {
+		t.Fatal(installErr)
+	}
+	t.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+
+	jsonServer := newStaticServer(t, http.StatusOK, "application/json", []byte(`{"rates":{"USD":"36.12345678"}}`))
+	bcvService := NewBCVService(&config.Config{ScrapeMaxAttempts: 1}, NewMemoryRateRepository(), NewNotificationDispatcherWith(),
+		NewNotificationDispatcherWith(), []RateSource{NewJSONRateSource(jsonServer.URL, "rates", "")}, NewMemoryQuarantineRepository())
+
+	if !bcvService.refreshRates(context.Background(), false) {
+		t.Fatal("refreshRates no guardó la tasa obtenida")
+	}
+	if flushErr := tracerProvider.ForceFlush(context.Background()); flushErr != nil {
+		t.Fatal(flushErr)
+	}
+
+	spansByName := map[string]tracetest.SpanStub{}
+	for _, recordedSpan := range spanExporter.GetSpans() {
+		spansByName[recordedSpan.Name] = recordedSpan
+	}
+	refreshSpan, refreshFound := spansByName["bcv.refresh_rates"]
+	fetchSpan, fetchFound := spansByName["rate_source.fetch"]
+	if !refreshFound || !fetchFound {
+		t.Fatalf("spans = %v; se esperaban bcv.refresh_rates y rate_source.fetch", spanExporter.GetSpans().Snapshots())
+	}
+	if fetchSpan.Parent.SpanID() != refreshSpan.SpanContext.SpanID() || fetchSpan.SpanContext.TraceID() != refreshSpan.SpanContext.TraceID() {
+		t.Fatalf("el padre de rate_source.fetch es %s; se esperaba el span bcv.refresh_rates %s", fetchSpan.Parent.SpanID(), refreshSpan.SpanContext.SpanID())
+	}
+}
+
+// newFailingRefreshService crea un BCVService cuya única fuente siempre responde 500, y retorna el servidor para
+// contar los intentos de scrapeo.
+func newFailingRefreshService(t *testing.T, appConfig *config.Config) (*BCVService, *countingServer) {
+	t.Helper()
+	failingServer := newCountingServer(t, http.StatusInternalServerError, []byte("error"))
+	bcvService := NewBCVService(appConfig, NewMemoryRateRepository(), NewNotificationDispatcherWith(),
+		NewNotificationDispatcherWith(), []RateSource{NewJSONRateSource(failingServer.URL, "rates", "")}, NewMemoryQuarantineRepository())
+	t.Cleanup(func() { bcvService.Stop(context.Background()) })
+	return bcvService, failingServer
+}
+
+// waitForRequests espera hasta que 'server' reciba 'expectedRequests' peticiones, y falla si no ocurre en 5 segundos.
+func waitForRequests(t *testing.T, server *countingServer, expectedRequests int32) {
+	t.Helper()
+	waitDeadline := time.Now().Add(5 * time.Second)
+	for server.requestCount.Load() < expectedRequests {
+		if time.Now().After(waitDeadline) {
+			t.Fatalf("la fuente recibió %d peticiones; se esperaban %d", server.requestCount.Load(), expectedRequests)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFetchRatesWithRetryAttempts(t *testing.T) {
+	bcvService, failingServer := newFailingRefreshService(t, &config.Config{
+		ScrapeMaxAttempts: 3, ScrapeInitialBackoff: time.Millisecond, ScrapeMaxBackoff: 2 * time.Millisecond,
+	})
+
+	publication := bcvService.fetchRatesWithRetry(context.Background())
+	if _, hasUSD := publication.Rates[DefaultCurrency]; hasUSD {
+		t.Fatalf("publicación = %+v; se esperaba sin la tasa del dólar", publication)
+	}
+	if requestCount := failingServer.requestCount.Load(); requestCount != 3 {
+		t.Fatalf("la fuente recibió %d peticiones; se esperaban 3 intentos", requestCount)
+	}
+}
+
+func TestStopInterruptsRetryBackoff(t *testing.T) {
+	bcvService, failingServer := newFailingRefreshService(t, &config.Config{
+		ScrapeMaxAttempts: 5, ScrapeInitialBackoff: 30 * time.Second, ScrapeMaxBackoff: 30 * time.Second,
+		ScrapeRetryInterval: time.Hour, ScrapeMaxFollowUps: 3,
+	})
+
+	updateFinished := make(chan struct{})
+	go func() {
+		bcvService.UpdateBCV()
+		close(updateFinished)
+	}()
+	waitForRequests(t, failingServer, 1) // La actualización ya está esperando para el segundo intento.
+
+	stopContext, cancelStop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelStop()
+	if stopErr := bcvService.Stop(stopContext); stopErr != nil {
+		t.Fatalf("Stop retornó %v; se esperaba que interrumpiera la espera entre intentos", stopErr)
+	}
+	<-updateFinished
+	if requestCount := failingServer.requestCount.Load(); requestCount != 1 {
+		t.Fatalf("la fuente recibió %d peticiones tras Stop; se esperaba 1", requestCount)
+	}
+	bcvService.followUpMutex.Lock()
+	defer bcvService.followUpMutex.Unlock()
+	if bcvService.followUpTimer != nil {
+		t.Fatal("se programó un reintento después de Stop")
+	}
+}
+
+func TestUpdateBCVFollowUps(t *testing.T) {
+	bcvService, failingServer := newFailingRefreshService(t, &config.Config{
+		ScrapeMaxAttempts: 1, ScrapeRetryInterval: 10 * time.Millisecond, ScrapeMaxFollowUps: 2,
+	})
+
+	bcvService.UpdateBCV()
+	waitForRequests(t, failingServer, 3) // La ejecución del cron y sus 2 reintentos.
+	time.Sleep(100 * time.Millisecond)
+	if requestCount := failingServer.requestCount.Load(); requestCount != 3 {
+		t.Fatalf("la fuente recibió %d peticiones; se esperaban 3 (sin reintentos después del límite)", requestCount)
+	}
+}
+
+func TestScheduleFollowUpKeepsSingleChain(t *testing.T) {
+	bcvService, failingServer := newFailingRefreshService(t, &config.Config{
+		ScrapeMaxAttempts: 1, ScrapeRetryInterval: 20 * time.Millisecond, ScrapeMaxFollowUps: 3,
+	})
+
+	// Un reintento y una ejecución del cron que fallan a la vez programan cada uno un reintento;
+	// solo debe quedar el último. Con 2 de 3 reintentos usados, la cadena hace 1 actualización y luego
+	// programa el tercero, que falla y agota el límite: 2 actualizaciones en total.
+	bcvService.scheduleFollowUp(context.Background())
+	bcvService.scheduleFollowUp(context.Background())
+	waitForRequests(t, failingServer, 2)
+	time.Sleep(150 * time.Millisecond)
+	if requestCount := failingServer.requestCount.Load(); requestCount != 2 {
+		t.Fatalf("la fuente recibió %d peticiones; se esperaban 2 (una sola cadena de reintentos)", requestCount)
+	}
+}
@@ -0,0 +1,516 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"precio-bcv-go/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltRatesBucket es el bucket de bbolt donde se guardan los registros de tasas, serializados en JSON.
+var boltRatesBucket = []byte("bcv_rates")
+
+// BoltRateRepository guarda las tasas BCV en un archivo local bbolt embebido,
+// para despliegues pequeños que no cuentan con un servidor MongoDB.
+type BoltRateRepository struct {
+	database *bolt.DB
+}
+
+// NewBoltRateRepository abre (o crea) el archivo bbolt indicado y prepara su bucket de tasas.
+func NewBoltRateRepository(databasePath string) (*BoltRateRepository, error) {
+	boltDatabase, openErr := bolt.Open(databasePath, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if openErr != nil {
+		return nil, fmt.Errorf("error al abrir la base de datos bbolt '%s': %w", databasePath, openErr)
+	}
+
+	bucketErr := boltDatabase.Update(func(boltTx *bolt.Tx) error {
+		_, createErr := boltTx.CreateBucketIfNotExists(boltRatesBucket)
+		return createErr
+	})
+	if bucketErr != nil {
+		boltDatabase.Close()
+		return nil, fmt.Errorf("error al crear el bucket de tasas en bbolt: %w", bucketErr)
+	}
+
+	slog.Info("Usando almacenamiento bbolt para las tasas BCV.", "path", databasePath)
+	return &BoltRateRepository{database: boltDatabase}, nil
+}
+
+// Ping verifica que el archivo bbolt siga abierto y legible.
+func (repository *BoltRateRepository) Ping(operationContext context.Context) error {
+	return repository.database.View(func(boltTx *bolt.Tx) error {
+		if boltTx.Bucket(boltRatesBucket) == nil {
+			return fmt.Errorf("el bucket de tasas no existe")
+		}
+		return nil
+	})
+}
+
+// Disconnect cierra el archivo bbolt. bbolt espera a que terminen las transacciones abiertas; no usa el contexto.
+func (repository *BoltRateRepository) Disconnect(operationContext context.Context) {
+	if closeErr := repository.database.Close(); closeErr != nil {
+		slog.Error("Error al cerrar la base de datos bbolt", "error", closeErr)
+		return
+	}
+	slog.Info("Base de datos bbolt cerrada.")
+}
+
+// SaveBCVRate guarda un nuevo registro de tasas en bbolt, usando una secuencia como clave.
+func (repository *BoltRateRepository) SaveBCVRate(operationContext context.Context, bcvRateDocument models.BCVRate) error {
+	saveErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		return putRateRecord(boltTx.Bucket(boltRatesBucket), &bcvRateDocument)
+	})
+	if saveErr != nil {
+		return fmt.Errorf("error al guardar BCVRate en bbolt: %w", saveErr)
+	}
+	slog.InfoContext(operationContext, "BCVRate guardado en bbolt", "usd", bcvRateDocument.Value, "currencies", len(bcvRateDocument.Rates), "timestamp", bcvRateDocument.Timestamp)
+	return nil
+}
+
+// putRateRecord guarda el registro en el bucket de tasas con la siguiente secuencia como clave y como ID.
+func putRateRecord(ratesBucket *bolt.Bucket, bcvRateDocument *models.BCVRate) error {
+	bcvRateDocument.Value = bcvRateDocument.RateFor("USD")
+	bcvRateDocument.Timestamp = bcvRateDocument.Timestamp.UTC()
+	bcvRateDocument.EffectiveDate = bcvRateDocument.EffectiveDate.UTC()
+
+	recordSequence, sequenceErr := ratesBucket.NextSequence()
+	if sequenceErr != nil {
+		return sequenceErr
+	}
+	bcvRateDocument.ID = strconv.FormatUint(recordSequence, 10)
+
+	encodedRecord, marshalErr := json.Marshal(bcvRateDocument)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	recordKey := make([]byte, 8)
+	binary.BigEndian.PutUint64(recordKey, recordSequence)
+	return ratesBucket.Put(recordKey, encodedRecord)
+}
+
+// GetLatestBCVRate obtiene el registro BCV más reciente, o nil si no hay registros.
+func (repository *BoltRateRepository) GetLatestBCVRate(operationContext context.Context) (*models.BCVRate, error) {
+	rateRecords, loadErr := repository.loadRateRecords()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return latestRateRecord(rateRecords), nil
+}
+
+// GetBCVRateForToday obtiene el registro BCV del día actual, o nil si no existe.
+func (repository *BoltRateRepository) GetBCVRateForToday(operationContext context.Context) (*models.BCVRate, error) {
+	rateRecords, loadErr := repository.loadRateRecords()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return todayRateRecord(rateRecords), nil
+}
+
+// GetBCVRateInEffectOn obtiene el registro vigente en el instante indicado según su "Fecha Valor".
+func (repository *BoltRateRepository) GetBCVRateInEffectOn(operationContext context.Context, effectiveTimestamp time.Time) (*models.BCVRate, error) {
+	rateRecords, loadErr := repository.loadRateRecords()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return rateRecordInEffectOn(rateRecords, effectiveTimestamp), nil
+}
+
+// GetBCVRateOn obtiene el registro más reciente cuya fecha valor cae en el día indicado.
+func (repository *BoltRateRepository) GetBCVRateOn(operationContext context.Context, effectiveDay time.Time) (*models.BCVRate, error) {
+	rateRecords, loadErr := repository.loadRateRecords()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return latestRateRecordOn(rateRecords, effectiveDay), nil
+}
+
+// GetBCVRateHistory obtiene los registros guardados entre dos instantes, paginados.
+func (repository *BoltRateRepository) GetBCVRateHistory(operationContext context.Context, fromTimestamp, toTimestamp time.Time, pageNumber, pageSize int) ([]models.BCVRate, int64, error) {
+	rateRecords, loadErr := repository.loadRateRecords()
+	if loadErr != nil {
+		return nil, 0, loadErr
+	}
+	historyRecords, totalRecords := rateHistoryPage(rateRecords, fromTimestamp, toTimestamp, pageNumber, pageSize)
+	return historyRecords, totalRecords, nil
+}
+
+// DeleteBCVRatesOn elimina los registros cuya fecha valor cae en el día indicado y retorna cuántos eliminó.
+func (repository *BoltRateRepository) DeleteBCVRatesOn(operationContext context.Context, effectiveDay time.Time) (int64, error) {
+	var deletedCount int64
+	deleteErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		var deleteErr error
+		deletedCount, deleteErr = deleteRateRecordsOn(boltTx.Bucket(boltRatesBucket), effectiveDay)
+		return deleteErr
+	})
+	if deleteErr != nil {
+		return 0, fmt.Errorf("error al eliminar los registros de BCVRate de bbolt: %w", deleteErr)
+	}
+	return deletedCount, nil
+}
+
+// ReplaceBCVRatesOn reemplaza los registros del día indicado por 'bcvRateDocument' y retorna cuántos reemplazó.
+// Ambos pasos ocurren en la misma transacción: si alguno falla, no se aplica ninguno.
+func (repository *BoltRateRepository) ReplaceBCVRatesOn(operationContext context.Context, effectiveDay time.Time, bcvRateDocument models.BCVRate) (int64, error) {
+	var replacedCount int64
+	replaceErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		ratesBucket := boltTx.Bucket(boltRatesBucket)
+		var deleteErr error
+		if replacedCount, deleteErr = deleteRateRecordsOn(ratesBucket, effectiveDay); deleteErr != nil {
+			return deleteErr
+		}
+		return putRateRecord(ratesBucket, &bcvRateDocument)
+	})
+	if replaceErr != nil {
+		return 0, fmt.Errorf("error al reemplazar los registros de BCVRate en bbolt: %w", replaceErr)
+	}
+	slog.InfoContext(operationContext, "BCVRate reemplazado en bbolt", "usd", bcvRateDocument.Value, "effective_date", bcvRateDocument.EffectiveDate, "replaced_records", replacedCount)
+	return replacedCount, nil
+}
+
+// deleteRateRecordsOn elimina del bucket de tasas los registros del día indicado y retorna cuántos eliminó.
+func deleteRateRecordsOn(ratesBucket *bolt.Bucket, effectiveDay time.Time) (int64, error) {
+	recordKeys := [][]byte{}
+	scanErr := ratesBucket.ForEach(func(recordKey, encodedRecord []byte) error {
+		var rateRecord models.BCVRate
+		if unmarshalErr := json.Unmarshal(encodedRecord, &rateRecord); unmarshalErr != nil {
+			return unmarshalErr
+		}
+		if isRecordOnDay(rateRecord, effectiveDay) {
+			recordKeys = append(recordKeys, append([]byte(nil), recordKey...))
+		}
+		return nil
+	})
+	if scanErr != nil {
+		return 0, scanErr
+	}
+	for _, recordKey := range recordKeys {
+		if deleteErr := ratesBucket.Delete(recordKey); deleteErr != nil {
+			return 0, deleteErr
+		}
+	}
+	return int64(len(recordKeys)), nil
+}
+
+// loadRateRecords lee todos los registros del bucket. Se guarda un registro por día,
+// por lo que el volumen es pequeño y las consultas se resuelven en memoria.
+func (repository *BoltRateRepository) loadRateRecords() ([]models.BCVRate, error) {
+	rateRecords := []models.BCVRate{}
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltRatesBucket).ForEach(func(recordKey, encodedRecord []byte) error {
+			var rateRecord models.BCVRate
+			if unmarshalErr := json.Unmarshal(encodedRecord, &rateRecord); unmarshalErr != nil {
+				return unmarshalErr
+			}
+			rateRecords = append(rateRecords, rateRecord)
+			return nil
+		})
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer los registros de BCVRate de bbolt: %w", viewErr)
+	}
+	return rateRecords, nil
+}
+
+// boltPlansBucket es el bucket de bbolt donde se guarda el catálogo de planes, con el ID del plan como clave.
+var boltPlansBucket = []byte("plans")
+
+// BoltPlanRepository guarda el catálogo de planes en el mismo archivo bbolt que las tasas.
+type BoltPlanRepository struct {
+	database *bolt.DB
+}
+
+// NewBoltPlanRepository prepara el bucket de planes en una base de datos bbolt ya abierta.
+func NewBoltPlanRepository(boltDatabase *bolt.DB) (*BoltPlanRepository, error) {
+	bucketErr := boltDatabase.Update(func(boltTx *bolt.Tx) error {
+		_, createErr := boltTx.CreateBucketIfNotExists(boltPlansBucket)
+		return createErr
+	})
+	if bucketErr != nil {
+		return nil, fmt.Errorf("error al crear el bucket de planes en bbolt: %w", bucketErr)
+	}
+	return &BoltPlanRepository{database: boltDatabase}, nil
+}
+
+// ListPlans retorna todos los planes, activos o no.
+func (repository *BoltPlanRepository) ListPlans(operationContext context.Context) ([]models.Plan, error) {
+	plans := []models.Plan{}
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltPlansBucket).ForEach(func(planKey, encodedPlan []byte) error {
+			var plan models.Plan
+			if unmarshalErr := json.Unmarshal(encodedPlan, &plan); unmarshalErr != nil {
+				return unmarshalErr
+			}
+			plans = append(plans, plan)
+			return nil
+		})
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer los planes de bbolt: %w", viewErr)
+	}
+	sortPlans(plans)
+	return plans, nil
+}
+
+// GetPlan retorna el plan con el ID indicado, o nil si no existe.
+func (repository *BoltPlanRepository) GetPlan(operationContext context.Context, planID string) (*models.Plan, error) {
+	var foundPlan *models.Plan
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		encodedPlan := boltTx.Bucket(boltPlansBucket).Get([]byte(planID))
+		if encodedPlan == nil {
+			return nil
+		}
+		foundPlan = &models.Plan{}
+		return json.Unmarshal(encodedPlan, foundPlan)
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer el plan '%s' de bbolt: %w", planID, viewErr)
+	}
+	return foundPlan, nil
+}
+
+// SavePlan crea o reemplaza el plan con su ID.
+func (repository *BoltPlanRepository) SavePlan(operationContext context.Context, plan models.Plan) error {
+	encodedPlan, marshalErr := json.Marshal(plan)
+	if marshalErr != nil {
+		return fmt.Errorf("error al serializar el plan '%s': %w", plan.ID, marshalErr)
+	}
+	saveErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltPlansBucket).Put([]byte(plan.ID), encodedPlan)
+	})
+	if saveErr != nil {
+		return fmt.Errorf("error al guardar el plan '%s' en bbolt: %w", plan.ID, saveErr)
+	}
+	return nil
+}
+
+// DeletePlan elimina el plan indicado. Retorna false si no existía.
+func (repository *BoltPlanRepository) DeletePlan(operationContext context.Context, planID string) (bool, error) {
+	planExisted := false
+	deleteErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		plansBucket := boltTx.Bucket(boltPlansBucket)
+		planExisted = plansBucket.Get([]byte(planID)) != nil
+		return plansBucket.Delete([]byte(planID))
+	})
+	if deleteErr != nil {
+		return false, fmt.Errorf("error al eliminar el plan '%s' de bbolt: %w", planID, deleteErr)
+	}
+	return planExisted, nil
+}
+
+// boltQuarantineBucket es el bucket de bbolt donde se guardan las tasas en cuarentena, con su ID como clave.
+var boltQuarantineBucket = []byte("quarantined_rates")
+
+// BoltQuarantineRepository guarda las tasas en cuarentena en el mismo archivo bbolt que las tasas.
+type BoltQuarantineRepository struct {
+	database *bolt.DB
+}
+
+// NewBoltQuarantineRepository prepara el bucket de cuarentena en una base de datos bbolt ya abierta.
+func NewBoltQuarantineRepository(boltDatabase *bolt.DB) (*BoltQuarantineRepository, error) {
+	bucketErr := boltDatabase.Update(func(boltTx *bolt.Tx) error {
+		_, createErr := boltTx.CreateBucketIfNotExists(boltQuarantineBucket)
+		return createErr
+	})
+	if bucketErr != nil {
+		return nil, fmt.Errorf("error al crear el bucket de cuarentena en bbolt: %w", bucketErr)
+	}
+	return &BoltQuarantineRepository{database: boltDatabase}, nil
+}
+
+// ListQuarantinedRates retorna todas las tasas en cuarentena, revisadas o no.
+func (repository *BoltQuarantineRepository) ListQuarantinedRates(operationContext context.Context) ([]models.QuarantinedRate, error) {
+	quarantinedRates := []models.QuarantinedRate{}
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltQuarantineBucket).ForEach(func(quarantineKey, encodedRate []byte) error {
+			var quarantinedRate models.QuarantinedRate
+			if unmarshalErr := json.Unmarshal(encodedRate, &quarantinedRate); unmarshalErr != nil {
+				return unmarshalErr
+			}
+			quarantinedRates = append(quarantinedRates, quarantinedRate)
+			return nil
+		})
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer las tasas en cuarentena de bbolt: %w", viewErr)
+	}
+	sortQuarantinedRates(quarantinedRates)
+	return quarantinedRates, nil
+}
+
+// GetQuarantinedRate retorna la tasa en cuarentena con el ID indicado, o nil si no existe.
+func (repository *BoltQuarantineRepository) GetQuarantinedRate(operationContext context.Context, quarantineID string) (*models.QuarantinedRate, error) {
+	var foundRate *models.QuarantinedRate
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		encodedRate := boltTx.Bucket(boltQuarantineBucket).Get([]byte(quarantineID))
+		if encodedRate == nil {
+			return nil
+		}
+		foundRate = &models.QuarantinedRate{}
+		return json.Unmarshal(encodedRate, foundRate)
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer la tasa en cuarentena '%s' de bbolt: %w", quarantineID, viewErr)
+	}
+	return foundRate, nil
+}
+
+// SaveQuarantinedRate crea o reemplaza la tasa en cuarentena con su ID.
+func (repository *BoltQuarantineRepository) SaveQuarantinedRate(operationContext context.Context, quarantinedRate models.QuarantinedRate) error {
+	encodedRate, marshalErr := json.Marshal(quarantinedRate)
+	if marshalErr != nil {
+		return fmt.Errorf("error al serializar la tasa en cuarentena '%s': %w", quarantinedRate.ID, marshalErr)
+	}
+	saveErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltQuarantineBucket).Put([]byte(quarantinedRate.ID), encodedRate)
+	})
+	if saveErr != nil {
+		return fmt.Errorf("error al guardar la tasa en cuarentena '%s' en bbolt: %w", quarantinedRate.ID, saveErr)
+	}
+	return nil
+}
+
+// SaveQuarantinedRateIfStatus reemplaza la tasa en cuarentena solo si su estado guardado es 'expectedStatus'.
+// La lectura y la escritura ocurren en la misma transacción. Retorna false si no existe o tiene otro estado.
+func (repository *BoltQuarantineRepository) SaveQuarantinedRateIfStatus(operationContext context.Context, quarantinedRate models.QuarantinedRate, expectedStatus string) (bool, error) {
+	encodedRate, marshalErr := json.Marshal(quarantinedRate)
+	if marshalErr != nil {
+		return false, fmt.Errorf("error al serializar la tasa en cuarentena '%s': %w", quarantinedRate.ID, marshalErr)
+	}
+	rateSaved := false
+	saveErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		quarantineBucket := boltTx.Bucket(boltQuarantineBucket)
+		storedEncodedRate := quarantineBucket.Get([]byte(quarantinedRate.ID))
+		if storedEncodedRate == nil {
+			return nil
+		}
+		var storedRate models.QuarantinedRate
+		if unmarshalErr := json.Unmarshal(storedEncodedRate, &storedRate); unmarshalErr != nil {
+			return unmarshalErr
+		}
+		if storedRate.Status != expectedStatus {
+			return nil
+		}
+		rateSaved = true
+		return quarantineBucket.Put([]byte(quarantinedRate.ID), encodedRate)
+	})
+	if saveErr != nil {
+		return false, fmt.Errorf("error al guardar la tasa en cuarentena '%s' en bbolt: %w", quarantinedRate.ID, saveErr)
+	}
+	return rateSaved, nil
+}
+
+// boltAPIKeysBucket es el bucket de bbolt donde se guardan las claves de API, con su ID como clave.
+var boltAPIKeysBucket = []byte("api_keys")
+
+// BoltAPIKeyRepository guarda las claves de API en el mismo archivo bbolt que las tasas.
+type BoltAPIKeyRepository struct {
+	database *bolt.DB
+}
+
+// NewBoltAPIKeyRepository prepara el bucket de claves de API en una base de datos bbolt ya abierta.
+func NewBoltAPIKeyRepository(boltDatabase *bolt.DB) (*BoltAPIKeyRepository, error) {
+	bucketErr := boltDatabase.Update(func(boltTx *bolt.Tx) error {
+		_, createErr := boltTx.CreateBucketIfNotExists(boltAPIKeysBucket)
+		return createErr
+	})
+	if bucketErr != nil {
+		return nil, fmt.Errorf("error al crear el bucket de claves de API en bbolt: %w", bucketErr)
+	}
+	return &BoltAPIKeyRepository{database: boltDatabase}, nil
+}
+
+// ListAPIKeys retorna todas las claves, vigentes o revocadas.
+func (repository *BoltAPIKeyRepository) ListAPIKeys(operationContext context.Context) ([]models.APIKey, error) {
+	apiKeys := []models.APIKey{}
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltAPIKeysBucket).ForEach(func(apiKeyID, encodedKey []byte) error {
+			var apiKey models.APIKey
+			if unmarshalErr := unmarshalAPIKey(encodedKey, &apiKey); unmarshalErr != nil {
+				return unmarshalErr
+			}
+			apiKeys = append(apiKeys, apiKey)
+			return nil
+		})
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer las claves de API de bbolt: %w", viewErr)
+	}
+	sortAPIKeys(apiKeys)
+	return apiKeys, nil
+}
+
+// GetAPIKey retorna la clave con el ID indicado, o nil si no existe.
+func (repository *BoltAPIKeyRepository) GetAPIKey(operationContext context.Context, apiKeyID string) (*models.APIKey, error) {
+	var foundKey *models.APIKey
+	viewErr := repository.database.View(func(boltTx *bolt.Tx) error {
+		encodedKey := boltTx.Bucket(boltAPIKeysBucket).Get([]byte(apiKeyID))
+		if encodedKey == nil {
+			return nil
+		}
+		foundKey = &models.APIKey{}
+		return unmarshalAPIKey(encodedKey, foundKey)
+	})
+	if viewErr != nil {
+		return nil, fmt.Errorf("error al leer la clave de API '%s' de bbolt: %w", apiKeyID, viewErr)
+	}
+	return foundKey, nil
+}
+
+// GetAPIKeyByHash retorna la clave con el hash indicado, o nil si no existe.
+func (repository *BoltAPIKeyRepository) GetAPIKeyByHash(operationContext context.Context, keyHash string) (*models.APIKey, error) {
+	apiKeys, listErr := repository.ListAPIKeys(operationContext)
+	if listErr != nil {
+		return nil, listErr
+	}
+	for _, apiKey := range apiKeys {
+		if apiKey.KeyHash == keyHash {
+			return &apiKey, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveAPIKey crea o reemplaza la clave con su ID.
+func (repository *BoltAPIKeyRepository) SaveAPIKey(operationContext context.Context, apiKey models.APIKey) error {
+	encodedKey, marshalErr := marshalAPIKey(apiKey)
+	if marshalErr != nil {
+		return fmt.Errorf("error al serializar la clave de API '%s': %w", apiKey.ID, marshalErr)
+	}
+	saveErr := repository.database.Update(func(boltTx *bolt.Tx) error {
+		return boltTx.Bucket(boltAPIKeysBucket).Put([]byte(apiKey.ID), encodedKey)
+	})
+	if saveErr != nil {
+		return fmt.Errorf("error al guardar la clave de API '%s' en bbolt: %w", apiKey.ID, saveErr)
+	}
+	return nil
+}
+
+// boltAPIKeyRecord es la forma en que se serializa una clave en bbolt. El hash se excluye del JSON
+// de models.APIKey para no exponerlo en la API, por lo que aquí se agrega explícitamente.
+type boltAPIKeyRecord struct {
+	models.APIKey
+	KeyHash string `json:"key_hash"`
+}
+
+// marshalAPIKey serializa una clave de API para guardarla en bbolt, incluido su hash.
+func marshalAPIKey(apiKey models.APIKey) ([]byte, error) {
+	return json.Marshal(boltAPIKeyRecord{APIKey: apiKey, KeyHash: apiKey.KeyHash})
+}
+
+// unmarshalAPIKey interpreta una clave de API guardada en bbolt, incluido su hash.
+func unmarshalAPIKey(encodedKey []byte, apiKey *models.APIKey) error {
+	var keyRecord boltAPIKeyRecord
+	if unmarshalErr := json.Unmarshal(encodedKey, &keyRecord); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	*apiKey = keyRecord.APIKey
+	apiKey.KeyHash = keyRecord.KeyHash
+	return nil
+}
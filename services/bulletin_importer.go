@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// BulletinImportSource es la fuente registrada en las tasas importadas de boletines históricos.
+const BulletinImportSource = "bcv_xls_import"
+
+// BulletinImportReport resume una importación de boletines históricos: cuántos días se guardaron,
+// cuántos ya existían con las mismas tasas y cuántos existían con tasas distintas.
+type BulletinImportReport struct {
+	Inserted    int
+	Skipped     int
+	Conflicting int
+	Conflicts   []string // Detalle de cada conflicto; el registro existente no se modifica.
+}
+
+// ImportRatePublications guarda en el repositorio las publicaciones cuya Fecha Valor aún no tiene registro.
+// Un día ya guardado con las mismas tasas se omite; con tasas distintas se reporta como conflicto y se deja intacto.
+// Las publicaciones se procesan por fecha, de modo que un día repetido en varios archivos se guarda una sola vez.
+func ImportRatePublications(importContext context.Context, rateRepository RateRepository, publications []RatePublication) (BulletinImportReport, error) {
+	sortedPublications := append([]RatePublication(nil), publications...)
+	sort.SliceStable(sortedPublications, func(i, j int) bool {
+		return sortedPublications[i].EffectiveDate.Before(sortedPublications[j].EffectiveDate)
+	})
+
+	importReport := BulletinImportReport{}
+	for _, publication := range sortedPublications {
+		effectiveDay := publication.EffectiveDate.Format("2006-01-02")
+
+		// Se busca un registro de ese mismo día (los registros sin fecha valor cuentan por su timestamp);
+		// el registro vigente en ese día podría ser el de un día anterior.
+		publicationDate := publication.EffectiveDate
+		startOfDay := time.Date(publicationDate.Year(), publicationDate.Month(), publicationDate.Day(), 0, 0, 0, 0, publicationDate.Location())
+		existingRecord, searchErr := rateRepository.GetBCVRateOn(importContext, startOfDay)
+		if searchErr != nil {
+			return importReport, fmt.Errorf("error al buscar la tasa del %s: %w", effectiveDay, searchErr)
+		}
+
+		if existingRecord != nil {
+			existingRates := ratesFromRecord(existingRecord.Rates, existingRecord.RateFor(DefaultCurrency))
+			if differences := differingRates(existingRates, publication.Rates); len(differences) > 0 {
+				importReport.Conflicting++
+				importReport.Conflicts = append(importReport.Conflicts, fmt.Sprintf("%s: %v", effectiveDay, differences))
+				slog.WarnContext(importContext, "La tasa ya existe con valores distintos. No se modifica.", "effective_date", effectiveDay, "differences", differences)
+			} else {
+				importReport.Skipped++
+			}
+			continue
+		}
+
+		// Un registro histórico se marca con su propia fecha valor, salvo que sea futura
+		// (el BCV publica la tasa del lunes el viernes), para no pasar por delante de la tasa actual.
+		recordTimestamp := publication.EffectiveDate
+		if currentTime := time.Now(); recordTimestamp.After(currentTime) {
+			recordTimestamp = currentTime
+		}
+		saveErr := rateRepository.SaveBCVRate(importContext, models.BCVRate{
+			Rates:         publication.Rates,
+			EffectiveDate: publication.EffectiveDate,
+			Timestamp:     recordTimestamp,
+			Source:        BulletinImportSource,
+		})
+		if saveErr != nil {
+			return importReport, fmt.Errorf("error al guardar la tasa del %s: %w", effectiveDay, saveErr)
+		}
+		importReport.Inserted++
+	}
+	return importReport, nil
+}
+
+// differingRates lista las monedas publicadas en ambos conjuntos cuyas tasas no coinciden exactamente.
+func differingRates(existingRates, importedRates map[string]decimal.Decimal) []string {
+	currencies := make([]string, 0, len(importedRates))
+	for currency := range importedRates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	differences := []string{}
+	for _, currency := range currencies {
+		existingRate, published := existingRates[currency]
+		if published && !existingRate.Equal(importedRates[currency]) {
+			differences = append(differences, fmt.Sprintf("%s guardada=%s boletín=%s", currency, existingRate, importedRates[currency]))
+		}
+	}
+	return differences
+}
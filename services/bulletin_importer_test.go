@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestImportRatePublications(t *testing.T) {
+	dayAt := func(day, hour int) time.Time { return time.Date(2024, 3, day, hour, 0, 0, 0, time.Local) }
+	usdRates := func(rateText string) map[string]decimal.Decimal {
+		return map[string]decimal.Decimal{DefaultCurrency: decimal.RequireFromString(rateText)}
+	}
+	existingRecords := []models.BCVRate{
+		{Value: decimal.RequireFromString("36.10"), Timestamp: dayAt(18, 9)}, // Registro anterior sin fecha valor.
+		{Value: decimal.RequireFromString("36.20"), Rates: usdRates("36.20"), EffectiveDate: dayAt(19, 0), Timestamp: dayAt(18, 16)},
+	}
+	publications := []RatePublication{
+		{Rates: usdRates("36.30"), EffectiveDate: dayAt(20, 0)}, // Día nuevo.
+		{Rates: usdRates("36.00"), EffectiveDate: dayAt(15, 0)}, // Día nuevo.
+		{Rates: usdRates("36.00"), EffectiveDate: dayAt(15, 0)}, // Mismo día en otro archivo.
+		{Rates: usdRates("36.1"), EffectiveDate: dayAt(18, 0)},  // Igual al registro sin fecha valor.
+		{Rates: usdRates("36.25"), EffectiveDate: dayAt(19, 0)}, // Distinto al registro guardado.
+	}
+
+	for backendName, rateRepository := range rateRepositoryBackends(t) {
+		t.Run(backendName, func(t *testing.T) {
+			for _, existingRecord := range existingRecords {
+				if saveErr := rateRepository.SaveBCVRate(context.Background(), existingRecord); saveErr != nil {
+					t.Fatal(saveErr)
+				}
+			}
+
+			importReport, importErr := ImportRatePublications(context.Background(), rateRepository, publications)
+			if importErr != nil {
+				t.Fatalf("ImportRatePublications retornó un error: %v", importErr)
+			}
+			if importReport.Inserted != 2 || importReport.Skipped != 2 || importReport.Conflicting != 1 || len(importReport.Conflicts) != 1 {
+				t.Fatalf("reporte = %+v; se esperaban 2 insertados, 2 omitidos y 1 conflicto", importReport)
+			}
+
+			_, totalRecords, historyErr := rateRepository.GetBCVRateHistory(context.Background(), dayAt(1, 0), dayAt(31, 0), 1, 10)
+			if historyErr != nil {
+				t.Fatal(historyErr)
+			}
+			if totalRecords != 4 {
+				t.Fatalf("hay %d registros guardados; se esperaban 4 (2 existentes y 2 importados)", totalRecords)
+			}
+			conflictingRecord, lookupErr := rateRepository.GetBCVRateOn(context.Background(), dayAt(19, 0))
+			if lookupErr != nil {
+				t.Fatal(lookupErr)
+			}
+			if conflictingRecord == nil || conflictingRecord.RateFor(DefaultCurrency).String() != "36.2" {
+				t.Fatalf("registro del día en conflicto = %+v; se esperaba el original intacto", conflictingRecord)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"precio-bcv-go/config"
+)
+
+// smtpSendTimeout es el plazo máximo de un envío completo (conexión, STARTTLS, autenticación y mensaje),
+// salvo que el contexto del envío venza antes.
+const smtpSendTimeout = 30 * time.Second
+
+// EmailNotifier envía alertas por correo electrónico a través de un servidor SMTP.
+type EmailNotifier struct {
+	serverAddress string
+	host          string
+	username      string
+	password      string
+	fromAddress   string
+	toAddresses   []string
+}
+
+// NewEmailNotifier crea un canal de correo con el servidor SMTP y los destinatarios configurados.
+func NewEmailNotifier(appConfig *config.Config) *EmailNotifier {
+	return &EmailNotifier{
+		serverAddress: net.JoinHostPort(appConfig.SMTPHost, appConfig.SMTPPort),
+		host:          appConfig.SMTPHost,
+		username:      appConfig.SMTPUsername,
+		password:      appConfig.SMTPPassword,
+		fromAddress:   appConfig.SMTPFrom,
+		toAddresses:   appConfig.SMTPTo,
+	}
+}
+
+// Name retorna el nombre del canal.
+func (notifier *EmailNotifier) Name() string {
+	return "email"
+}
+
+// SendAlert envía el mensaje como correo de texto plano a todos los destinatarios, con el asunto que
+// corresponde a 'kind'. Si hay usuario configurado se autentica con PLAIN; se usa STARTTLS cuando el servidor lo ofrece.
+func (notifier *EmailNotifier) SendAlert(alertContext context.Context, kind NotificationKind, message string) error {
+	var smtpAuth smtp.Auth
+	if notifier.username != "" {
+		smtpAuth = smtp.PlainAuth("", notifier.username, notifier.password, notifier.host)
+	}
+
+	emailBody := strings.Join([]string{
+		"From: " + notifier.fromAddress,
+		"To: " + strings.Join(notifier.toAddresses, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", kind.Subject()),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		message,
+	}, "\r\n")
+
+	sendErr := notifier.sendMail(alertContext, smtpAuth, []byte(emailBody))
+	if sendErr != nil {
+		return fmt.Errorf("error al enviar alerta por correo: %w", sendErr)
+	}
+
+	slog.InfoContext(alertContext, "Alerta por correo enviada exitosamente.", "recipients", len(notifier.toAddresses))
+	return nil
+}
+
+// sendMail hace lo mismo que smtp.SendMail, pero respetando 'sendContext': la conexión se abre con un
+// net.Dialer, tiene como plazo el menor entre smtpSendTimeout y el del contexto, y se cierra si el contexto
+// se cancela, para que un servidor SMTP que no responde no deje la goroutine de la alerta bloqueada.
+func (notifier *EmailNotifier) sendMail(sendContext context.Context, smtpAuth smtp.Auth, emailBody []byte) error {
+	sendDeadline := time.Now().Add(smtpSendTimeout)
+	if contextDeadline, hasDeadline := sendContext.Deadline(); hasDeadline && contextDeadline.Before(sendDeadline) {
+		sendDeadline = contextDeadline
+	}
+	dialer := &net.Dialer{Deadline: sendDeadline}
+	smtpConnection, dialErr := dialer.DialContext(sendContext, "tcp", notifier.serverAddress)
+	if dialErr != nil {
+		return dialErr
+	}
+	defer smtpConnection.Close()
+	if deadlineErr := smtpConnection.SetDeadline(sendDeadline); deadlineErr != nil {
+		return deadlineErr
+	}
+	stopClosing := context.AfterFunc(sendContext, func() { smtpConnection.Close() })
+	defer stopClosing()
+
+	smtpClient, clientErr := smtp.NewClient(smtpConnection, notifier.host)
+	if clientErr != nil {
+		return clientErr
+	}
+	defer smtpClient.Close()
+
+	if hasStartTLS, _ := smtpClient.Extension("STARTTLS"); hasStartTLS {
+		if tlsErr := smtpClient.StartTLS(&tls.Config{ServerName: notifier.host}); tlsErr != nil {
+			return tlsErr
+		}
+	}
+	if smtpAuth != nil {
+		if authErr := smtpClient.Auth(smtpAuth); authErr != nil {
+			return authErr
+		}
+	}
+	if mailErr := smtpClient.Mail(notifier.fromAddress); mailErr != nil {
+		return mailErr
+	}
+	for _, toAddress := range notifier.toAddresses {
+		if rcptErr := smtpClient.Rcpt(toAddress); rcptErr != nil {
+			return rcptErr
+		}
+	}
+	bodyWriter, dataErr := smtpClient.Data()
+	if dataErr != nil {
+		return dataErr
+	}
+	if _, writeErr := bodyWriter.Write(emailBody); writeErr != nil {
+		return writeErr
+	}
+	if closeErr := bodyWriter.Close(); closeErr != nil {
+		return closeErr
+	}
+	return smtpClient.Quit()
+}
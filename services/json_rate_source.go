@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// JSONRateSource obtiene las tasas de una API HTTP que responde JSON, como un espejo propio
+// o un proveedor externo. 'ratesField' es la ruta con puntos (ej. "data.rates") a un objeto
+// moneda→tasa; las tasas pueden venir como números o como texto. 'dateField' es opcional.
+type JSONRateSource struct {
+	sourceURL  string
+	ratesField string
+	dateField  string
+	client     *http.Client
+}
+
+// NewJSONRateSource crea la fuente JSON con la URL y las rutas de campos indicadas.
+func NewJSONRateSource(sourceURL, ratesField, dateField string) *JSONRateSource {
+	return &JSONRateSource{
+		sourceURL:  sourceURL,
+		ratesField: ratesField,
+		dateField:  dateField,
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name retorna el nombre de la fuente.
+func (source *JSONRateSource) Name() string {
+	return "json"
+}
+
+// FetchRates consulta la API y retorna las tasas de las monedas soportadas que encuentre.
+func (source *JSONRateSource) FetchRates(fetchContext context.Context) (RatePublication, error) {
+	sourceRequest, requestErr := http.NewRequestWithContext(fetchContext, http.MethodGet, source.sourceURL, nil)
+	if requestErr != nil {
+		return RatePublication{}, fmt.Errorf("error al crear la solicitud a %s: %w", source.sourceURL, requestErr)
+	}
+	resp, getErr := source.client.Do(sourceRequest)
+	if getErr != nil {
+		return RatePublication{}, fmt.Errorf("error al consultar %s: %w", source.sourceURL, getErr)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return RatePublication{}, fmt.Errorf("error al consultar %s: código de estado %d", source.sourceURL, resp.StatusCode)
+	}
+
+	// Con UseNumber los números conservan su texto original y se convierten a decimal sin pasar por float64.
+	var responseBody any
+	responseDecoder := json.NewDecoder(resp.Body)
+	responseDecoder.UseNumber()
+	if decodeErr := responseDecoder.Decode(&responseBody); decodeErr != nil {
+		return RatePublication{}, fmt.Errorf("error al interpretar la respuesta de %s: %w", source.sourceURL, decodeErr)
+	}
+
+	ratesObject, isObject := jsonField(responseBody, source.ratesField).(map[string]any)
+	if !isObject {
+		return RatePublication{}, fmt.Errorf("la respuesta de %s no tiene un objeto de tasas en '%s'", source.sourceURL, source.ratesField)
+	}
+	publication := RatePublication{Rates: map[string]decimal.Decimal{}}
+	for fieldName, fieldValue := range ratesObject {
+		currency := strings.ToUpper(fieldName)
+		if _, supported := SupportedCurrencies[currency]; !supported {
+			continue
+		}
+		if currencyRate, parsed := jsonNumber(fieldValue); parsed && currencyRate.IsPositive() {
+			publication.Rates[currency] = currencyRate
+		}
+	}
+
+	if source.dateField != "" {
+		if dateText, isText := jsonField(responseBody, source.dateField).(string); isText {
+			publication.EffectiveDate = parseJSONDate(dateText)
+		}
+	}
+	return publication, nil
+}
+
+// jsonField recorre un valor JSON decodificado siguiendo una ruta con puntos. Retorna nil si no existe.
+func jsonField(jsonValue any, fieldPath string) any {
+	if fieldPath == "" {
+		return jsonValue
+	}
+	for _, fieldName := range strings.Split(fieldPath, ".") {
+		jsonObject, isObject := jsonValue.(map[string]any)
+		if !isObject {
+			return nil
+		}
+		jsonValue = jsonObject[fieldName]
+	}
+	return jsonValue
+}
+
+// jsonNumber convierte un número JSON, o un texto numérico en cualquiera de los dos formatos decimales.
+func jsonNumber(jsonValue any) (decimal.Decimal, bool) {
+	switch typedValue := jsonValue.(type) {
+	case json.Number:
+		parsedNumber, parseErr := decimal.NewFromString(typedValue.String())
+		return parsedNumber, parseErr == nil
+	case string:
+		parsedNumber, parseErr := parseBulletinNumber(typedValue)
+		return parsedNumber, parseErr == nil
+	}
+	return decimal.Zero, false
+}
+
+// parseJSONDate interpreta una fecha RFC 3339, "aaaa-mm-dd" o "dd/mm/aaaa". Retorna cero si no la reconoce.
+func parseJSONDate(dateText string) time.Time {
+	dateText = strings.TrimSpace(dateText)
+	if parsedDate, parseErr := time.Parse(time.RFC3339, dateText); parseErr == nil {
+		return parsedDate
+	}
+	if parsedDate, parseErr := time.ParseInLocation("2006-01-02", dateText, time.Local); parseErr == nil {
+		return parsedDate
+	}
+	return parseBulletinDate(dateText)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newStaticServer crea un servidor httptest que responde siempre 'statusCode' con 'responseBody'.
+func newStaticServer(t *testing.T, statusCode int, contentType string, responseBody []byte) *httptest.Server {
+	t.Helper()
+	staticServer := httptest.NewServer(http.HandlerFunc(func(httpResponseWriter http.ResponseWriter, _ *http.Request) {
+		httpResponseWriter.Header().Set("Content-Type", contentType)
+		httpResponseWriter.WriteHeader(statusCode)
+		httpResponseWriter.Write(responseBody)
+	}))
+	t.Cleanup(staticServer.Close)
+	return staticServer
+}
+
+func TestJSONRateSourceFetchRates(t *testing.T) {
+	responseBody := []byte(`{"data":{"fecha":"18/03/2024","rates":{"usd":36.12345678,"EUR":"39,26457118","CNY":"5.01765463","GBP":45.1,"TRY":-1}}}`)
+	jsonServer := newStaticServer(t, http.StatusOK, "application/json", responseBody)
+
+	publication, fetchErr := NewJSONRateSource(jsonServer.URL, "data.rates", "data.fecha").FetchRates(context.Background())
+	if fetchErr != nil {
+		t.Fatalf("FetchRates retornó un error: %v", fetchErr)
+	}
+	// GBP no es una moneda soportada y TRY no es positiva; ambas se omiten. Los números conservan todos sus decimales.
+	expectedRates := map[string]string{"USD": "36.12345678", "EUR": "39.26457118", "CNY": "5.01765463"}
+	if len(publication.Rates) != len(expectedRates) {
+		t.Fatalf("tasas = %v; se esperaba %v", publication.Rates, expectedRates)
+	}
+	for currency, expectedRate := range expectedRates {
+		if publication.Rates[currency].String() != expectedRate {
+			t.Fatalf("tasa de %s = %s; se esperaba %s", currency, publication.Rates[currency], expectedRate)
+		}
+	}
+	if publication.EffectiveDate.Format("2006-01-02") != "2024-03-18" {
+		t.Fatalf("fecha valor = %s; se esperaba 2024-03-18", publication.EffectiveDate)
+	}
+}
+
+func TestJSONRateSourceErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		statusCode   int
+		responseBody string
+	}{
+		{name: "código de estado de error", statusCode: http.StatusBadGateway, responseBody: `{"rates":{"USD":36.1}}`},
+		{name: "cuerpo que no es JSON", statusCode: http.StatusOK, responseBody: `<html>mantenimiento</html>`},
+		{name: "JSON truncado", statusCode: http.StatusOK, responseBody: `{"rates":{"USD":36.`},
+		{name: "sin objeto de tasas", statusCode: http.StatusOK, responseBody: `{"rates":[36.1]}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			jsonServer := newStaticServer(t, testCase.statusCode, "application/json", []byte(testCase.responseBody))
+			if _, fetchErr := NewJSONRateSource(jsonServer.URL, "rates", "").FetchRates(context.Background()); fetchErr == nil {
+				t.Fatal("FetchRates no retornó un error")
+			}
+		})
+	}
+}
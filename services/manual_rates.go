@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Errores retornados por la carga manual de tasas, para que los manejadores HTTP elijan el código de estado.
+var (
+	ErrInvalidManualRate = errors.New("tasa manual inválida")
+	ErrRateNotFound      = errors.New("no hay tasas registradas para esa fecha")
+)
+
+// SetManualRate registra a mano las tasas con fecha valor 'effectiveDay' (medianoche, hora local),
+// reemplazando las que hubiera para ese día. Sirve tanto para cargar una tasa cuando el sitio del BCV
+// no responde como para corregir una ya guardada. Retorna el registro guardado y si reemplazó uno existente.
+// 'author' es el nombre declarado por quien hace la carga, sin verificar. 'credential' identifica el token de
+// administración con el que se autenticó (ver handlers.AdminCredentialFromContext); como el token es compartido,
+// solo indica que la carga fue autenticada y con qué token, no qué persona la hizo.
+// Las tasas actuales del servicio se recalculan de inmediato.
+func (service *BCVService) SetManualRate(requestContext context.Context, effectiveDay time.Time, manualRates map[string]decimal.Decimal, author, credential, note string) (models.BCVRate, bool, error) {
+	author = strings.TrimSpace(author)
+	if author == "" {
+		return models.BCVRate{}, false, fmt.Errorf("%w: el autor es requerido", ErrInvalidManualRate)
+	}
+	if credential == "" {
+		return models.BCVRate{}, false, fmt.Errorf("%w: la petición no está autenticada", ErrInvalidManualRate)
+	}
+	if !manualRates[DefaultCurrency].IsPositive() {
+		return models.BCVRate{}, false, fmt.Errorf("%w: la tasa de %s es requerida y debe ser mayor que 0", ErrInvalidManualRate, DefaultCurrency)
+	}
+	for currency, currencyRate := range manualRates {
+		if _, supported := SupportedCurrencies[currency]; !supported {
+			return models.BCVRate{}, false, fmt.Errorf("%w: moneda no soportada '%s'", ErrInvalidManualRate, currency)
+		}
+		if !currencyRate.IsPositive() {
+			return models.BCVRate{}, false, fmt.Errorf("%w: la tasa de %s debe ser mayor que 0", ErrInvalidManualRate, currency)
+		}
+	}
+
+	service.updateMutex.Lock()
+	defer service.updateMutex.Unlock()
+
+	// Una tasa de hoy o de una fecha futura se marca con la hora actual, para que pase a ser la tasa vigente
+	// y UpdateBCV la encuentre como la tasa del día. Una corrección de una fecha pasada conserva su fecha,
+	// para no desplazar a la tasa actual.
+	recordTimestamp := effectiveDay
+	currentTime := time.Now().In(time.Local)
+	if startOfCurrentDay := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, currentTime.Location()); !effectiveDay.Before(startOfCurrentDay) {
+		recordTimestamp = currentTime
+	}
+	manualRecord := models.BCVRate{
+		Value:         manualRates[DefaultCurrency],
+		Rates:         manualRates,
+		EffectiveDate: effectiveDay,
+		Timestamp:     recordTimestamp,
+		Source:        RateSourceManual,
+		Author:        author,
+		Credential:    credential,
+		Note:          strings.TrimSpace(note),
+	}
+	// El registro nuevo se guarda antes de retirar los anteriores, por lo que un fallo no deja el día sin tasas.
+	replacedCount, replaceErr := service.dbService.ReplaceBCVRatesOn(requestContext, effectiveDay, manualRecord)
+	if replaceErr != nil {
+		return models.BCVRate{}, false, replaceErr
+	}
+	slog.InfoContext(requestContext, "Tasa manual registrada", "effective_date", effectiveDay.Format("2006-01-02"), "author", author, "credential", credential, "bcv", manualRates[DefaultCurrency], "replaced_records", replacedCount)
+
+	service.reloadCurrentRates(requestContext)
+	return manualRecord, replacedCount > 0, nil
+}
+
+// DeleteRate elimina las tasas con fecha valor 'effectiveDay' y recalcula de inmediato las tasas actuales.
+// Retorna ErrRateNotFound si no había tasas para ese día.
+func (service *BCVService) DeleteRate(requestContext context.Context, effectiveDay time.Time) error {
+	service.updateMutex.Lock()
+	defer service.updateMutex.Unlock()
+
+	deletedCount, deleteErr := service.dbService.DeleteBCVRatesOn(requestContext, effectiveDay)
+	if deleteErr != nil {
+		return deleteErr
+	}
+	if deletedCount == 0 {
+		return ErrRateNotFound
+	}
+	slog.InfoContext(requestContext, "Registros de tasas eliminados", "effective_date", effectiveDay.Format("2006-01-02"), "deleted_records", deletedCount)
+
+	service.reloadCurrentRates(requestContext)
+	return nil
+}
+
+// reloadCurrentRates vuelve a tomar como tasas actuales el registro más reciente de la base de datos
+// y avisa a los suscriptores si cambiaron. Debe llamarse con updateMutex tomado.
+func (service *BCVService) reloadCurrentRates(reloadContext context.Context) {
+	latestRecord, latestSearchErr := service.dbService.GetLatestBCVRate(reloadContext)
+	if latestSearchErr != nil {
+		slog.WarnContext(reloadContext, "Error al recargar las tasas actuales de la base de datos", "error", latestSearchErr)
+		return
+	}
+
+	reloadedRates := map[string]decimal.Decimal{}
+	var reloadedRateDate, reloadedFetchedAt time.Time
+	reloadedSource := ""
+	usingFallback := false
+	if latestRecord != nil {
+		reloadedRates = ratesFromRecord(latestRecord.Rates, latestRecord.RateFor(DefaultCurrency))
+		reloadedRateDate = recordEffectiveDate(*latestRecord)
+		reloadedFetchedAt = latestRecord.Timestamp
+		// Si el registro más reciente no es de hoy, se informa como un valor anterior.
+		usingFallback = !sameDay(latestRecord.Timestamp, time.Now())
+		if usingFallback {
+			reloadedSource = recordRateSource(*latestRecord, RateSourceDBFallback)
+		} else {
+			reloadedSource = recordRateSource(*latestRecord, RateSourceDBToday)
+		}
+	}
+
+	previousSnapshot := service.publishRates(newRateSnapshot(reloadedRates, reloadedRateDate, reloadedFetchedAt, reloadedSource, usingFallback, service.maxRateAge))
+
+	slog.InfoContext(reloadContext, "BCV interno recargado", "bcv", reloadedRates[DefaultCurrency], "currencies", len(reloadedRates))
+	service.notifyRateChange(reloadContext, previousSnapshot.Rates, reloadedRates, reloadedRateDate)
+}
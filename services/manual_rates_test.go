@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestReplaceBCVRatesOn(t *testing.T) {
+	boltRepository, openErr := NewBoltRateRepository(filepath.Join(t.TempDir(), "rates.db"))
+	if openErr != nil {
+		t.Fatal(openErr)
+	}
+	t.Cleanup(func() { boltRepository.Disconnect(context.Background()) })
+
+	repositories := map[string]RateRepository{"memoria": NewMemoryRateRepository(), "bbolt": boltRepository}
+	replacedDay := time.Date(2024, 3, 18, 0, 0, 0, 0, time.Local)
+	for repositoryName, rateRepository := range repositories {
+		t.Run(repositoryName, func(t *testing.T) {
+			operationContext := context.Background()
+			for _, storedRecord := range []models.BCVRate{
+				{Rates: map[string]decimal.Decimal{"USD": decimal.RequireFromString("36.1")}, EffectiveDate: replacedDay, Timestamp: replacedDay.Add(time.Hour)},
+				{Rates: map[string]decimal.Decimal{"USD": decimal.RequireFromString("36.2")}, EffectiveDate: replacedDay, Timestamp: replacedDay.Add(2 * time.Hour)},
+				{Rates: map[string]decimal.Decimal{"USD": decimal.RequireFromString("36.3")}, EffectiveDate: replacedDay.AddDate(0, 0, 1), Timestamp: replacedDay.AddDate(0, 0, 1)},
+			} {
+				if saveErr := rateRepository.SaveBCVRate(operationContext, storedRecord); saveErr != nil {
+					t.Fatal(saveErr)
+				}
+			}
+
+			replacementRecord := models.BCVRate{Rates: map[string]decimal.Decimal{"USD": decimal.RequireFromString("36.15")}, EffectiveDate: replacedDay, Timestamp: replacedDay, Source: RateSourceManual}
+			replacedCount, replaceErr := rateRepository.ReplaceBCVRatesOn(operationContext, replacedDay, replacementRecord)
+			if replaceErr != nil || replacedCount != 2 {
+				t.Fatalf("ReplaceBCVRatesOn = %d, %v; se esperaba 2 registros reemplazados", replacedCount, replaceErr)
+			}
+
+			historyRecords, totalRecords, historyErr := rateRepository.GetBCVRateHistory(operationContext, replacedDay, replacedDay.AddDate(0, 0, 2), 1, 10)
+			if historyErr != nil || totalRecords != 2 {
+				t.Fatalf("historial con %d registros (%v); se esperaban 2", totalRecords, historyErr)
+			}
+			if historyRecords[0].RateFor("USD").String() != "36.15" || historyRecords[1].RateFor("USD").String() != "36.3" {
+				t.Fatalf("tasas = %s y %s; se esperaban 36.15 (reemplazo) y 36.3 (otro día)", historyRecords[0].RateFor("USD"), historyRecords[1].RateFor("USD"))
+			}
+		})
+	}
+}
+
+func TestSetManualRateRecordsCredential(t *testing.T) {
+	bcvService := NewBCVService(&config.Config{}, NewMemoryRateRepository(), NewNotificationDispatcherWith(),
+		NewNotificationDispatcherWith(), nil, NewMemoryQuarantineRepository())
+	effectiveDay := time.Date(2024, 3, 18, 0, 0, 0, 0, time.Local)
+	manualRates := map[string]decimal.Decimal{"USD": decimal.RequireFromString("36.12")}
+
+	if _, _, setErr := bcvService.SetManualRate(context.Background(), effectiveDay, manualRates, "Ana", "", ""); !errors.Is(setErr, ErrInvalidManualRate) {
+		t.Fatalf("SetManualRate sin credencial retornó %v; se esperaba ErrInvalidManualRate", setErr)
+	}
+
+	storedRecord, replacedExisting, setErr := bcvService.SetManualRate(context.Background(), effectiveDay, manualRates, "Ana", "admin-token:3f2a9c0b1d4e", "corrección")
+	if setErr != nil || replacedExisting {
+		t.Fatalf("SetManualRate = %v, %v; se esperaba un registro nuevo", replacedExisting, setErr)
+	}
+	if storedRecord.Author != "Ana" || storedRecord.Credential != "admin-token:3f2a9c0b1d4e" {
+		t.Fatalf("autor %q y credencial %q; se esperaban los de la petición", storedRecord.Author, storedRecord.Credential)
+	}
+
+	_, replacedExisting, setErr = bcvService.SetManualRate(context.Background(), effectiveDay, manualRates, "Ana", "admin-token:3f2a9c0b1d4e", "")
+	if setErr != nil || !replacedExisting {
+		t.Fatalf("SetManualRate = %v, %v; se esperaba que reemplazara el registro anterior", replacedExisting, setErr)
+	}
+}
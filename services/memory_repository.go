@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"precio-bcv-go/models"
+)
+
+// MemoryRateRepository guarda las tasas BCV en memoria. Los datos se pierden al reiniciar,
+// por lo que está pensado para pruebas y despliegues pequeños sin base de datos.
+type MemoryRateRepository struct {
+	recordsMutex sync.RWMutex
+	rateRecords  []models.BCVRate
+	nextID       int
+}
+
+// NewMemoryRateRepository crea un repositorio de tasas vacío en memoria.
+func NewMemoryRateRepository() *MemoryRateRepository {
+	slog.Info("Usando almacenamiento en memoria para las tasas BCV.")
+	return &MemoryRateRepository{nextID: 1}
+}
+
+// Ping siempre tiene éxito; el almacenamiento en memoria no puede fallar.
+func (repository *MemoryRateRepository) Ping(operationContext context.Context) error { return nil }
+
+// Disconnect no realiza ninguna acción; existe para cumplir con RateRepository.
+func (repository *MemoryRateRepository) Disconnect(operationContext context.Context) {}
+
+// SaveBCVRate guarda un nuevo registro de tasas en memoria.
+func (repository *MemoryRateRepository) SaveBCVRate(operationContext context.Context, bcvRateDocument models.BCVRate) error {
+	repository.recordsMutex.Lock()
+	defer repository.recordsMutex.Unlock()
+
+	storedRecord := repository.appendRateRecord(bcvRateDocument)
+	slog.InfoContext(operationContext, "BCVRate guardado en memoria", "usd", storedRecord.Value, "currencies", len(storedRecord.Rates), "timestamp", storedRecord.Timestamp)
+	return nil
+}
+
+// appendRateRecord agrega una copia del registro con un ID nuevo y la retorna. Debe llamarse con recordsMutex tomado.
+func (repository *MemoryRateRepository) appendRateRecord(bcvRateDocument models.BCVRate) models.BCVRate {
+	storedRecord := *copyRateRecord(&bcvRateDocument)
+	storedRecord.ID = strconv.Itoa(repository.nextID)
+	storedRecord.Value = storedRecord.RateFor("USD")
+	storedRecord.Timestamp = storedRecord.Timestamp.UTC()
+	storedRecord.EffectiveDate = storedRecord.EffectiveDate.UTC()
+	repository.nextID++
+
+	repository.rateRecords = append(repository.rateRecords, storedRecord)
+	return storedRecord
+}
+
+// GetLatestBCVRate obtiene el registro BCV más reciente, o nil si no hay registros.
+func (repository *MemoryRateRepository) GetLatestBCVRate(operationContext context.Context) (*models.BCVRate, error) {
+	repository.recordsMutex.RLock()
+	defer repository.recordsMutex.RUnlock()
+	return copyRateRecord(latestRateRecord(repository.rateRecords)), nil
+}
+
+// GetBCVRateForToday obtiene el registro BCV del día actual, o nil si no existe.
+func (repository *MemoryRateRepository) GetBCVRateForToday(operationContext context.Context) (*models.BCVRate, error) {
+	repository.recordsMutex.RLock()
+	defer repository.recordsMutex.RUnlock()
+	return copyRateRecord(todayRateRecord(repository.rateRecords)), nil
+}
+
+// GetBCVRateInEffectOn obtiene el registro vigente en el instante indicado según su "Fecha Valor".
+func (repository *MemoryRateRepository) GetBCVRateInEffectOn(operationContext context.Context, effectiveTimestamp time.Time) (*models.BCVRate, error) {
+	repository.recordsMutex.RLock()
+	defer repository.recordsMutex.RUnlock()
+	return copyRateRecord(rateRecordInEffectOn(repository.rateRecords, effectiveTimestamp)), nil
+}
+
+// GetBCVRateOn obtiene el registro más reciente cuya fecha valor cae en el día indicado.
+func (repository *MemoryRateRepository) GetBCVRateOn(operationContext context.Context, effectiveDay time.Time) (*models.BCVRate, error) {
+	repository.recordsMutex.RLock()
+	defer repository.recordsMutex.RUnlock()
+	return copyRateRecord(latestRateRecordOn(repository.rateRecords, effectiveDay)), nil
+}
+
+// GetBCVRateHistory obtiene los registros guardados entre dos instantes, paginados.
+func (repository *MemoryRateRepository) GetBCVRateHistory(operationContext context.Context, fromTimestamp, toTimestamp time.Time, pageNumber, pageSize int) ([]models.BCVRate, int64, error) {
+	repository.recordsMutex.RLock()
+	defer repository.recordsMutex.RUnlock()
+
+	historyRecords, totalRecords := rateHistoryPage(repository.rateRecords, fromTimestamp, toTimestamp, pageNumber, pageSize)
+	copiedRecords := make([]models.BCVRate, 0, len(historyRecords))
+	for recordIndex := range historyRecords {
+		copiedRecords = append(copiedRecords, *copyRateRecord(&historyRecords[recordIndex]))
+	}
+	return copiedRecords, totalRecords, nil
+}
+
+// DeleteBCVRatesOn elimina los registros cuya fecha valor cae en el día indicado y retorna cuántos eliminó.
+func (repository *MemoryRateRepository) DeleteBCVRatesOn(operationContext context.Context, effectiveDay time.Time) (int64, error) {
+	repository.recordsMutex.Lock()
+	defer repository.recordsMutex.Unlock()
+	return repository.deleteRateRecordsOn(effectiveDay), nil
+}
+
+// ReplaceBCVRatesOn reemplaza los registros del día indicado por 'bcvRateDocument' y retorna cuántos reemplazó.
+// Ambos pasos ocurren con el mismo bloqueo, por lo que ningún lector ve el día sin registros.
+func (repository *MemoryRateRepository) ReplaceBCVRatesOn(operationContext context.Context, effectiveDay time.Time, bcvRateDocument models.BCVRate) (int64, error) {
+	repository.recordsMutex.Lock()
+	defer repository.recordsMutex.Unlock()
+
+	replacedCount := repository.deleteRateRecordsOn(effectiveDay)
+	storedRecord := repository.appendRateRecord(bcvRateDocument)
+	slog.InfoContext(operationContext, "BCVRate reemplazado en memoria", "usd", storedRecord.Value, "effective_date", storedRecord.EffectiveDate, "replaced_records", replacedCount)
+	return replacedCount, nil
+}
+
+// deleteRateRecordsOn elimina los registros del día indicado y retorna cuántos eliminó. Debe llamarse con recordsMutex tomado.
+func (repository *MemoryRateRepository) deleteRateRecordsOn(effectiveDay time.Time) int64 {
+	keptRecords := make([]models.BCVRate, 0, len(repository.rateRecords))
+	for _, rateRecord := range repository.rateRecords {
+		if !isRecordOnDay(rateRecord, effectiveDay) {
+			keptRecords = append(keptRecords, rateRecord)
+		}
+	}
+	deletedCount := int64(len(repository.rateRecords) - len(keptRecords))
+	repository.rateRecords = keptRecords
+	return deletedCount
+}
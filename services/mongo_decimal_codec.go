@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// decimalType es el tipo de las tasas y los montos en los documentos de MongoDB.
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+// newMongoRegistry retorna el registro de codecs BSON del cliente: el de la librería más el de decimal.Decimal.
+func newMongoRegistry() *bsoncodec.Registry {
+	mongoRegistry := bson.NewRegistry()
+	mongoRegistry.RegisterTypeEncoder(decimalType, bsoncodec.ValueEncoderFunc(encodeDecimal))
+	mongoRegistry.RegisterTypeDecoder(decimalType, bsoncodec.ValueDecoderFunc(decodeDecimal))
+	return mongoRegistry
+}
+
+// encodeDecimal guarda un decimal.Decimal como Decimal128, el tipo decimal exacto de MongoDB.
+func encodeDecimal(_ bsoncodec.EncodeContext, valueWriter bsonrw.ValueWriter, decimalValue reflect.Value) error {
+	if !decimalValue.IsValid() || decimalValue.Type() != decimalType {
+		return bsoncodec.ValueEncoderError{Name: "encodeDecimal", Types: []reflect.Type{decimalType}, Received: decimalValue}
+	}
+	decimalText := decimalValue.Interface().(decimal.Decimal).String()
+	decimal128Value, parseErr := primitive.ParseDecimal128(decimalText)
+	if parseErr != nil {
+		return fmt.Errorf("error al convertir %s a Decimal128: %w", decimalText, parseErr)
+	}
+	return valueWriter.WriteDecimal128(decimal128Value)
+}
+
+// decodeDecimal lee un decimal.Decimal de un Decimal128. También acepta los double y enteros de los documentos
+// guardados antes de usar decimales, y texto numérico; un null queda en 0.
+func decodeDecimal(_ bsoncodec.DecodeContext, valueReader bsonrw.ValueReader, decimalValue reflect.Value) error {
+	if !decimalValue.CanSet() || decimalValue.Type() != decimalType {
+		return bsoncodec.ValueDecoderError{Name: "decodeDecimal", Types: []reflect.Type{decimalType}, Received: decimalValue}
+	}
+
+	var decodedDecimal decimal.Decimal
+	switch valueType := valueReader.Type(); valueType {
+	case bsontype.Decimal128:
+		decimal128Value, readErr := valueReader.ReadDecimal128()
+		if readErr != nil {
+			return readErr
+		}
+		parsedDecimal, parseErr := decimal.NewFromString(decimal128Value.String())
+		if parseErr != nil {
+			return fmt.Errorf("Decimal128 inválido %s: %w", decimal128Value, parseErr)
+		}
+		decodedDecimal = parsedDecimal
+	case bsontype.Double:
+		doubleValue, readErr := valueReader.ReadDouble()
+		if readErr != nil {
+			return readErr
+		}
+		decodedDecimal = decimal.NewFromFloat(doubleValue) // Toma el decimal más corto que representa al double (ej. 36.1234).
+	case bsontype.Int32:
+		int32Value, readErr := valueReader.ReadInt32()
+		if readErr != nil {
+			return readErr
+		}
+		decodedDecimal = decimal.NewFromInt32(int32Value)
+	case bsontype.Int64:
+		int64Value, readErr := valueReader.ReadInt64()
+		if readErr != nil {
+			return readErr
+		}
+		decodedDecimal = decimal.NewFromInt(int64Value)
+	case bsontype.String:
+		stringValue, readErr := valueReader.ReadString()
+		if readErr != nil {
+			return readErr
+		}
+		parsedDecimal, parseErr := decimal.NewFromString(stringValue)
+		if parseErr != nil {
+			return fmt.Errorf("número inválido '%s': %w", stringValue, parseErr)
+		}
+		decodedDecimal = parsedDecimal
+	case bsontype.Null:
+		if readErr := valueReader.ReadNull(); readErr != nil {
+			return readErr
+		}
+	default:
+		return fmt.Errorf("no se puede leer un %s como decimal", valueType)
+	}
+
+	decimalValue.Set(reflect.ValueOf(decodedDecimal))
+	return nil
+}
@@ -3,151 +3,584 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"precio-bcv-go/config"
+	"precio-bcv-go/metrics"
 	"precio-bcv-go/models"
+	"precio-bcv-go/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MongoDBService maneja la conexión y operaciones CRUD con MongoDB.
 type MongoDBService struct {
-	client     *mongo.Client
-	collection *mongo.Collection
-	ctx        context.Context
-	cancel     context.CancelFunc 
+	client               *mongo.Client
+	collection           *mongo.Collection
+	plansCollection      *mongo.Collection
+	quarantineCollection *mongo.Collection
+	apiKeysCollection    *mongo.Collection
 }
 
 // NewMongoDBService inicializa un nuevo servicio de MongoDB.
 // Establece una conexión con un timeout y verifica su disponibilidad con un ping.
-func NewMongoDBService(appConfig *config.Config) (*MongoDBService, error) { 
+func NewMongoDBService(appConfig *config.Config) (*MongoDBService, error) {
 	// Establece un contexto con un timeout para la conexión inicial.
+	// Solo cubre la conexión y el ping; cada operación y el cierre usan el contexto de su llamador.
 	connectionCtx, cancelContext := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelContext()
 	// Configura las opciones del cliente de MongoDB, aplicando la URI de conexión.
-	clientOpts := options.Client().ApplyURI(appConfig.MongoDBURI) 
-	
+	clientOpts := options.Client().ApplyURI(appConfig.MongoDBURI)
+	// El monitor marca con error el span de la operación cuando uno de sus comandos falla.
+	clientOpts.SetMonitor(mongoCommandMonitor())
+	// Las tasas y los montos se guardan como Decimal128.
+	clientOpts.SetRegistry(newMongoRegistry())
+
 	// Intenta conectar a MongoDB.
-	mongoClient, connectErr := mongo.Connect(connectionCtx, clientOpts) 
+	mongoClient, connectErr := mongo.Connect(connectionCtx, clientOpts)
 	if connectErr != nil {
-		cancelContext() // Asegúrate de cancelar el contexto si la conexión falla.
 		return nil, fmt.Errorf("error al conectar a MongoDB: %w", connectErr)
 	}
 
 	// Realiza un ping para verificar que la conexión sea funcional.
-	pingErr := mongoClient.Ping(connectionCtx, nil) 
+	pingErr := mongoClient.Ping(connectionCtx, nil)
 	if pingErr != nil {
-		cancelContext() // Asegúrate de cancelar el contexto si el ping falla.
 		return nil, fmt.Errorf("error al hacer ping a MongoDB: %w", pingErr)
 	}
 
-	log.Println("Conectado a MongoDB!")
+	slog.Info("Conectado a MongoDB.", "database", appConfig.DatabaseName)
 
 	// Obtiene la referencia a la colección específica donde se almacenarán los datos del BCV.
 	bcvCollection := mongoClient.Database(appConfig.DatabaseName).Collection(appConfig.CollectionName)
+	// La colección del catálogo de planes vive en la misma base de datos.
+	plansCollection := mongoClient.Database(appConfig.DatabaseName).Collection(appConfig.PlansCollectionName)
+	quarantineCollection := mongoClient.Database(appConfig.DatabaseName).Collection(appConfig.QuarantineCollectionName)
+	apiKeysCollection := mongoClient.Database(appConfig.DatabaseName).Collection(appConfig.APIKeysCollectionName)
 
 	return &MongoDBService{
-		client:     mongoClient,
-		collection: bcvCollection,
-		ctx:        connectionCtx,
-		cancel:     cancelContext,
+		client:               mongoClient,
+		collection:           bcvCollection,
+		plansCollection:      plansCollection,
+		quarantineCollection: quarantineCollection,
+		apiKeysCollection:    apiKeysCollection,
 	}, nil
 }
 
-// Disconnect cierra la conexión con MongoDB y libera los recursos del contexto.
-func (service *MongoDBService) Disconnect() { 
+// startMongoSpan abre el span de una operación de MongoDBService sobre la colección indicada ("" si no aplica).
+func startMongoSpan(operationContext context.Context, operation, collectionName string) (context.Context, trace.Span) {
+	spanAttributes := []attribute.KeyValue{semconv.DBSystemNameMongoDB, semconv.DBOperationName(operation)}
+	if collectionName != "" {
+		spanAttributes = append(spanAttributes, semconv.DBCollectionName(collectionName))
+	}
+	return tracing.Tracer().Start(operationContext, "mongo."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttributes...))
+}
+
+// mongoCommandMonitor retorna un monitor de comandos que registra en el span de la operación en curso
+// cada comando enviado al servidor, y marca el span como fallido si alguno falla.
+func mongoCommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(commandContext context.Context, startedEvent *event.CommandStartedEvent) {
+			trace.SpanFromContext(commandContext).AddEvent("mongo.command", trace.WithAttributes(attribute.String("db.command", startedEvent.CommandName)))
+		},
+		Failed: func(commandContext context.Context, failedEvent *event.CommandFailedEvent) {
+			tracing.RecordError(trace.SpanFromContext(commandContext), fmt.Errorf("comando '%s' fallido: %s", failedEvent.CommandName, failedEvent.Failure))
+		},
+	}
+}
+
+// Ping verifica que el servidor de MongoDB responda.
+func (service *MongoDBService) Ping(operationContext context.Context) error {
+	defer metrics.ObserveMongoOperation("ping", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "ping", "")
+	defer operationSpan.End()
+	pingCtx, cancelPing := context.WithTimeout(operationContext, 2*time.Second) // Corto, para no demorar las sondas de Kubernetes.
+	defer cancelPing()
+	return service.client.Ping(pingCtx, nil)
+}
+
+// Disconnect cierra la conexión con MongoDB, esperando a que terminen las operaciones en curso
+// como máximo hasta que venza 'operationContext' (en el apagado, el plazo SHUTDOWN_GRACE_PERIOD).
+func (service *MongoDBService) Disconnect(operationContext context.Context) {
 	if service.client != nil {
-		disconnectErr := service.client.Disconnect(service.ctx) 
+		disconnectErr := service.client.Disconnect(operationContext)
 		if disconnectErr != nil {
-			log.Printf("Error al desconectar de MongoDB: %v", disconnectErr)
+			slog.ErrorContext(operationContext, "Error al desconectar de MongoDB", "error", disconnectErr)
 		}
-		service.cancel() // Llama a la función de cancelación del contexto.
-		log.Println("Desconectado de MongoDB.")
+		slog.InfoContext(operationContext, "Desconectado de MongoDB.")
 	}
 }
 
-// SaveBCVRate guarda un nuevo registro de la tasa BCV en MongoDB.
-func (service *MongoDBService) SaveBCVRate(rateValue float64, recordTimestamp time.Time) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // 10 segundos de timeout
-	defer cancel() // Importante para liberar los recursos del contexto
+// SaveBCVRate guarda un nuevo registro con las tasas BCV de cada moneda en MongoDB.
+// El campo Value se llena con la tasa del dólar para mantener compatibilidad con los documentos anteriores.
+func (service *MongoDBService) SaveBCVRate(operationContext context.Context, bcvRateDocument models.BCVRate) error {
+	defer metrics.ObserveMongoOperation("save_rate", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "save_rate", service.collection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()                                                       // Importante para liberar los recursos del contexto
 
-	bcvRateDocument := models.BCVRate{
-		Value: rateValue,
-		Timestamp: recordTimestamp.UTC(),
-	}
+	rateValue := bcvRateDocument.RateFor("USD")
+	bcvRateDocument.Value = rateValue
+	bcvRateDocument.Timestamp = bcvRateDocument.Timestamp.UTC()
+	bcvRateDocument.EffectiveDate = bcvRateDocument.EffectiveDate.UTC()
 
 	_, insertErr := service.collection.InsertOne(ctx, bcvRateDocument)
 
 	if insertErr != nil {
 		return fmt.Errorf("error al insertar BCVRate en MongoDB: %w", insertErr)
 	}
-	log.Printf("BCVRate %.4f guardado en MongoDB con fecha %s (UTC).", rateValue, recordTimestamp.UTC().Format("2006-01-02")) // Log también en UTC
+	slog.InfoContext(operationContext, "BCVRate guardado en MongoDB", "usd", rateValue, "currencies", len(bcvRateDocument.Rates), "timestamp", bcvRateDocument.Timestamp) // Fecha en UTC
 	return nil
 }
 
-func (service *MongoDBService) GetLatestBCVRate() (float64, error) {
+// GetLatestBCVRate obtiene el registro BCV más reciente.
+// Retorna nil y nil si la colección está vacía.
+func (service *MongoDBService) GetLatestBCVRate(operationContext context.Context) (*models.BCVRate, error) {
+	defer metrics.ObserveMongoOperation("get_latest_rate", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_latest_rate", service.collection.Name())
+	defer operationSpan.End()
 	var latestBCVRecord models.BCVRate
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // 10 segundos de timeout
-	defer cancel() // Importante para liberar los recursos del contexto
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()                                                       // Importante para liberar los recursos del contexto
 	// Ordena por timestamp descendente para obtener el documento más reciente.
 	findOptions := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
 	decodeErr := service.collection.FindOne(ctx, bson.M{}, findOptions).Decode(&latestBCVRecord)
-	
+
+	if decodeErr != nil {
+		if decodeErr == mongo.ErrNoDocuments {
+			return nil, nil // No se encontraron documentos, retorna nil y sin error.
+		}
+		return nil, fmt.Errorf("error al obtener la última BCVRate de MongoDB: %w", decodeErr)
+	}
+	return &latestBCVRecord, nil
+}
+
+// GetBCVRateForToday obtiene el registro BCV del día actual.
+// Retorna nil y nil si no se encuentra un registro para hoy.
+func (service *MongoDBService) GetBCVRateForToday(operationContext context.Context) (*models.BCVRate, error) {
+	defer metrics.ObserveMongoOperation("get_rate_for_today", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_rate_for_today", service.collection.Name())
+	defer operationSpan.End()
+	var bcvTodayRecord models.BCVRate
+
+	// Crea un nuevo contexto con un timeout para esta operación específica.
+	// Puedes ajustar la duración (ej. 5*time.Second, 10*time.Second) según la latencia de tu DB.
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second)
+	defer cancel() // Es crucial llamar a cancel para liberar recursos del contexto.
+
+	// Asegura que la fecha esté en la zona horaria local para una comparación precisa del día.
+	// Sin embargo, para MongoDB, es mejor trabajar con UTC para las consultas.
+	// Convertir startOfCurrentDay y endOfCurrentDay a UTC.
+	currentTime := time.Now().In(time.Local)
+
+	// Define el inicio y fin del día actual en la zona horaria local,
+	// y luego convertirlos a UTC para la consulta de MongoDB.
+	startOfCurrentDayLocal := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, currentTime.Location())
+	endOfCurrentDayLocal := startOfCurrentDayLocal.Add(24 * time.Hour).Add(-time.Nanosecond)
+
+	// Convertir a UTC para el filtro de la base de datos
+	startOfCurrentDayUTC := startOfCurrentDayLocal.UTC()
+	endOfCurrentDayUTC := endOfCurrentDayLocal.UTC()
+
+	// Crea el filtro para buscar documentos dentro del rango del día actual en UTC.
+	dayFilter := bson.M{
+		"timestamp": bson.M{
+			"$gte": startOfCurrentDayUTC, // Usar UTC
+			"$lte": endOfCurrentDayUTC,   // Usar UTC
+		},
+	}
+
+	// Ordena por timestamp descendente para obtener el valor más reciente si hay múltiples para el mismo día.
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	// Pasa el nuevo contexto 'ctx' a la operación de MongoDB.
+	decodeErr := service.collection.FindOne(ctx, dayFilter, findOptions).Decode(&bcvTodayRecord)
+	if decodeErr != nil {
+		if decodeErr == mongo.ErrNoDocuments {
+			return nil, nil // No hay un registro para hoy, retorna nil y sin error.
+		}
+		return nil, fmt.Errorf("error al obtener BCVRate para hoy de MongoDB: %w", decodeErr)
+	}
+	return &bcvTodayRecord, nil
+}
+
+// GetBCVRateInEffectOn obtiene el registro BCV vigente en el instante indicado según su "Fecha Valor":
+// el más reciente cuya fecha valor no sea posterior a ese instante. Así, fines de semana y feriados
+// resuelven al último valor publicado. Los documentos sin fecha valor usan su timestamp.
+// Retorna nil y nil si no hay ningún registro vigente.
+func (service *MongoDBService) GetBCVRateInEffectOn(operationContext context.Context, effectiveTimestamp time.Time) (*models.BCVRate, error) {
+	defer metrics.ObserveMongoOperation("get_rate_in_effect_on", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_rate_in_effect_on", service.collection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	// Se ordena por la fecha valor o, si el documento no la tiene, por su timestamp (igual que recordEffectiveDate),
+	// para que un documento sin fecha valor no quede detrás de cualquier documento anterior que sí la tenga.
+	effectivePipeline := mongo.Pipeline{
+		{{Key: "$addFields", Value: bson.M{"_effective_or_timestamp": bson.M{"$ifNull": bson.A{"$effective_date", "$timestamp"}}}}},
+		{{Key: "$match", Value: bson.M{"_effective_or_timestamp": bson.M{"$lte": effectiveTimestamp.UTC()}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_effective_or_timestamp", Value: -1}, {Key: "timestamp", Value: -1}}}},
+		{{Key: "$limit", Value: 1}},
+		{{Key: "$project", Value: bson.M{"_effective_or_timestamp": 0}}},
+	}
+	effectiveCursor, aggregateErr := service.collection.Aggregate(ctx, effectivePipeline)
+	if aggregateErr != nil {
+		return nil, fmt.Errorf("error al obtener la BCVRate vigente de MongoDB: %w", aggregateErr)
+	}
+	var effectiveRecords []models.BCVRate
+	if decodeErr := effectiveCursor.All(ctx, &effectiveRecords); decodeErr != nil {
+		return nil, fmt.Errorf("error al decodificar la BCVRate vigente de MongoDB: %w", decodeErr)
+	}
+	if len(effectiveRecords) == 0 {
+		return nil, nil // No hay un registro vigente para esa fecha.
+	}
+	return &effectiveRecords[0], nil
+}
+
+// GetBCVRateOn obtiene el registro BCV más reciente cuya fecha valor cae en el día que comienza en 'effectiveDay'
+// (o cuyo timestamp cae en ese día, si no tiene fecha valor). Retorna nil y nil si no hay ninguno.
+func (service *MongoDBService) GetBCVRateOn(operationContext context.Context, effectiveDay time.Time) (*models.BCVRate, error) {
+	defer metrics.ObserveMongoOperation("get_rate_on", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_rate_on", service.collection.Name())
+	defer operationSpan.End()
+	var dayRecord models.BCVRate
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	decodeErr := service.collection.FindOne(ctx, mongoDayFilter(effectiveDay), findOptions).Decode(&dayRecord)
+	if decodeErr != nil {
+		if decodeErr == mongo.ErrNoDocuments {
+			return nil, nil // No hay un registro para ese día.
+		}
+		return nil, fmt.Errorf("error al obtener la BCVRate del %s de MongoDB: %w", effectiveDay.Format("2006-01-02"), decodeErr)
+	}
+	return &dayRecord, nil
+}
+
+// GetBCVRateHistory obtiene los registros BCV guardados entre dos instantes (ambos inclusive, según su
+// timestamp de obtención, no su fecha valor), ordenados por fecha ascendente y paginados. Retorna también el total de registros en el rango.
+func (service *MongoDBService) GetBCVRateHistory(operationContext context.Context, fromTimestamp, toTimestamp time.Time, pageNumber, pageSize int) ([]models.BCVRate, int64, error) {
+	defer metrics.ObserveMongoOperation("get_rate_history", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_rate_history", service.collection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	rangeFilter := bson.M{
+		"timestamp": bson.M{
+			"$gte": fromTimestamp.UTC(),
+			"$lte": toTimestamp.UTC(),
+		},
+	}
+
+	totalRecords, countErr := service.collection.CountDocuments(ctx, rangeFilter)
+	if countErr != nil {
+		return nil, 0, fmt.Errorf("error al contar el historial de BCVRate en MongoDB: %w", countErr)
+	}
+	// Una página posterior a la última no necesita consultarse (y su salto podría desbordar un int64).
+	if int64(pageNumber-1) > totalRecords/int64(pageSize) {
+		return []models.BCVRate{}, totalRecords, nil
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}}).
+		SetSkip(int64(pageNumber-1) * int64(pageSize)).
+		SetLimit(int64(pageSize))
+
+	historyCursor, findErr := service.collection.Find(ctx, rangeFilter, findOptions)
+	if findErr != nil {
+		return nil, 0, fmt.Errorf("error al consultar el historial de BCVRate en MongoDB: %w", findErr)
+	}
+	defer historyCursor.Close(ctx)
+
+	historyRecords := []models.BCVRate{}
+	if decodeErr := historyCursor.All(ctx, &historyRecords); decodeErr != nil {
+		return nil, 0, fmt.Errorf("error al decodificar el historial de BCVRate de MongoDB: %w", decodeErr)
+	}
+	return historyRecords, totalRecords, nil
+}
+
+// DeleteBCVRatesOn elimina los documentos cuya fecha valor cae en el día que comienza en 'effectiveDay'
+// (o cuyo timestamp cae en ese día, si no tienen fecha valor) y retorna cuántos eliminó.
+func (service *MongoDBService) DeleteBCVRatesOn(operationContext context.Context, effectiveDay time.Time) (int64, error) {
+	defer metrics.ObserveMongoOperation("delete_rates_on", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "delete_rates_on", service.collection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	deleteResult, deleteErr := service.collection.DeleteMany(ctx, mongoDayFilter(effectiveDay))
+	if deleteErr != nil {
+		return 0, fmt.Errorf("error al eliminar BCVRate del %s en MongoDB: %w", effectiveDay.Format("2006-01-02"), deleteErr)
+	}
+	return deleteResult.DeletedCount, nil
+}
+
+// ReplaceBCVRatesOn reemplaza los documentos del día indicado por 'bcvRateDocument' y retorna cuántos reemplazó.
+// Primero inserta el documento nuevo y luego elimina los demás de ese día, para que el día nunca quede sin tasas.
+// Si la eliminación falla, se retira el documento insertado para no dejar dos tasas para el mismo día.
+func (service *MongoDBService) ReplaceBCVRatesOn(operationContext context.Context, effectiveDay time.Time, bcvRateDocument models.BCVRate) (int64, error) {
+	defer metrics.ObserveMongoOperation("replace_rates_on", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "replace_rates_on", service.collection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	bcvRateDocument.Value = bcvRateDocument.RateFor("USD")
+	bcvRateDocument.Timestamp = bcvRateDocument.Timestamp.UTC()
+	bcvRateDocument.EffectiveDate = bcvRateDocument.EffectiveDate.UTC()
+	insertResult, insertErr := service.collection.InsertOne(ctx, bcvRateDocument)
+	if insertErr != nil {
+		return 0, fmt.Errorf("error al insertar BCVRate en MongoDB: %w", insertErr)
+	}
+
+	olderRecordsFilter := bson.M{"$and": bson.A{mongoDayFilter(effectiveDay), bson.M{"_id": bson.M{"$ne": insertResult.InsertedID}}}}
+	deleteResult, deleteErr := service.collection.DeleteMany(ctx, olderRecordsFilter)
+	if deleteErr != nil {
+		if _, rollbackErr := service.collection.DeleteOne(ctx, bson.M{"_id": insertResult.InsertedID}); rollbackErr != nil {
+			slog.ErrorContext(operationContext, "Error al retirar el BCVRate insertado tras fallar el reemplazo", "error", rollbackErr)
+		}
+		return 0, fmt.Errorf("error al reemplazar BCVRate del %s en MongoDB: %w", effectiveDay.Format("2006-01-02"), deleteErr)
+	}
+	slog.InfoContext(operationContext, "BCVRate reemplazado en MongoDB", "usd", bcvRateDocument.Value, "effective_date", bcvRateDocument.EffectiveDate, "replaced_records", deleteResult.DeletedCount)
+	return deleteResult.DeletedCount, nil
+}
+
+// mongoDayFilter selecciona los documentos cuya fecha valor cae en el día que comienza en 'effectiveDay',
+// o cuyo timestamp cae en ese día si no tienen fecha valor.
+func mongoDayFilter(effectiveDay time.Time) bson.M {
+	dayRange := bson.M{"$gte": effectiveDay.UTC(), "$lt": effectiveDay.AddDate(0, 0, 1).UTC()}
+	return bson.M{
+		"$or": bson.A{
+			bson.M{"effective_date": dayRange},
+			bson.M{"effective_date": bson.M{"$exists": false}, "timestamp": dayRange},
+		},
+	}
+}
+
+// ListPlans obtiene todos los planes del catálogo, activos o no.
+func (service *MongoDBService) ListPlans(operationContext context.Context) ([]models.Plan, error) {
+	defer metrics.ObserveMongoOperation("list_plans", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "list_plans", service.plansCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	plansCursor, findErr := service.plansCollection.Find(ctx, bson.M{})
+	if findErr != nil {
+		return nil, fmt.Errorf("error al consultar los planes en MongoDB: %w", findErr)
+	}
+	defer plansCursor.Close(ctx)
+
+	plans := []models.Plan{}
+	if decodeErr := plansCursor.All(ctx, &plans); decodeErr != nil {
+		return nil, fmt.Errorf("error al decodificar los planes de MongoDB: %w", decodeErr)
+	}
+	sortPlans(plans)
+	return plans, nil
+}
+
+// GetPlan obtiene el plan con el ID indicado.
+// Retorna nil y nil si no existe.
+func (service *MongoDBService) GetPlan(operationContext context.Context, planID string) (*models.Plan, error) {
+	defer metrics.ObserveMongoOperation("get_plan", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_plan", service.plansCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	var plan models.Plan
+	decodeErr := service.plansCollection.FindOne(ctx, bson.M{"_id": planID}).Decode(&plan)
+	if decodeErr != nil {
+		if decodeErr == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener el plan '%s' de MongoDB: %w", planID, decodeErr)
+	}
+	return &plan, nil
+}
+
+// SavePlan crea o reemplaza el plan con su ID.
+func (service *MongoDBService) SavePlan(operationContext context.Context, plan models.Plan) error {
+	defer metrics.ObserveMongoOperation("save_plan", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "save_plan", service.plansCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	_, replaceErr := service.plansCollection.ReplaceOne(ctx, bson.M{"_id": plan.ID}, plan, options.Replace().SetUpsert(true))
+	if replaceErr != nil {
+		return fmt.Errorf("error al guardar el plan '%s' en MongoDB: %w", plan.ID, replaceErr)
+	}
+	return nil
+}
+
+// DeletePlan elimina el plan indicado. Retorna false si no existía.
+func (service *MongoDBService) DeletePlan(operationContext context.Context, planID string) (bool, error) {
+	defer metrics.ObserveMongoOperation("delete_plan", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "delete_plan", service.plansCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	deleteResult, deleteErr := service.plansCollection.DeleteOne(ctx, bson.M{"_id": planID})
+	if deleteErr != nil {
+		return false, fmt.Errorf("error al eliminar el plan '%s' de MongoDB: %w", planID, deleteErr)
+	}
+	return deleteResult.DeletedCount > 0, nil
+}
+
+// ListQuarantinedRates obtiene todas las tasas en cuarentena, revisadas o no, de la más reciente a la más antigua.
+func (service *MongoDBService) ListQuarantinedRates(operationContext context.Context) ([]models.QuarantinedRate, error) {
+	defer metrics.ObserveMongoOperation("list_quarantined_rates", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "list_quarantined_rates", service.quarantineCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "quarantined_at", Value: -1}})
+	quarantineCursor, findErr := service.quarantineCollection.Find(ctx, bson.M{}, findOptions)
+	if findErr != nil {
+		return nil, fmt.Errorf("error al consultar las tasas en cuarentena en MongoDB: %w", findErr)
+	}
+	defer quarantineCursor.Close(ctx)
+
+	quarantinedRates := []models.QuarantinedRate{}
+	if decodeErr := quarantineCursor.All(ctx, &quarantinedRates); decodeErr != nil {
+		return nil, fmt.Errorf("error al decodificar las tasas en cuarentena de MongoDB: %w", decodeErr)
+	}
+	return quarantinedRates, nil
+}
+
+// GetQuarantinedRate obtiene la tasa en cuarentena con el ID indicado.
+// Retorna nil y nil si no existe.
+func (service *MongoDBService) GetQuarantinedRate(operationContext context.Context, quarantineID string) (*models.QuarantinedRate, error) {
+	defer metrics.ObserveMongoOperation("get_quarantined_rate", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "get_quarantined_rate", service.quarantineCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	var quarantinedRate models.QuarantinedRate
+	decodeErr := service.quarantineCollection.FindOne(ctx, bson.M{"_id": quarantineID}).Decode(&quarantinedRate)
+	if decodeErr != nil {
+		if decodeErr == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error al obtener la tasa en cuarentena '%s' de MongoDB: %w", quarantineID, decodeErr)
+	}
+	return &quarantinedRate, nil
+}
+
+// SaveQuarantinedRate crea o reemplaza la tasa en cuarentena con su ID.
+func (service *MongoDBService) SaveQuarantinedRate(operationContext context.Context, quarantinedRate models.QuarantinedRate) error {
+	defer metrics.ObserveMongoOperation("save_quarantined_rate", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "save_quarantined_rate", service.quarantineCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	quarantinedRate.EffectiveDate = quarantinedRate.EffectiveDate.UTC()
+	quarantinedRate.QuarantinedAt = quarantinedRate.QuarantinedAt.UTC()
+	_, replaceErr := service.quarantineCollection.ReplaceOne(ctx, bson.M{"_id": quarantinedRate.ID}, quarantinedRate, options.Replace().SetUpsert(true))
+	if replaceErr != nil {
+		return fmt.Errorf("error al guardar la tasa en cuarentena '%s' en MongoDB: %w", quarantinedRate.ID, replaceErr)
+	}
+	return nil
+}
+
+// SaveQuarantinedRateIfStatus reemplaza la tasa en cuarentena solo si su estado guardado es 'expectedStatus',
+// con una única operación condicional. Retorna false si no existe o tiene otro estado.
+func (service *MongoDBService) SaveQuarantinedRateIfStatus(operationContext context.Context, quarantinedRate models.QuarantinedRate, expectedStatus string) (bool, error) {
+	defer metrics.ObserveMongoOperation("save_quarantined_rate_if_status", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "save_quarantined_rate_if_status", service.quarantineCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	quarantinedRate.EffectiveDate = quarantinedRate.EffectiveDate.UTC()
+	quarantinedRate.QuarantinedAt = quarantinedRate.QuarantinedAt.UTC()
+	replaceResult, replaceErr := service.quarantineCollection.ReplaceOne(ctx, bson.M{"_id": quarantinedRate.ID, "status": expectedStatus}, quarantinedRate)
+	if replaceErr != nil {
+		return false, fmt.Errorf("error al guardar la tasa en cuarentena '%s' en MongoDB: %w", quarantinedRate.ID, replaceErr)
+	}
+	return replaceResult.MatchedCount > 0, nil
+}
+
+// ListAPIKeys obtiene todas las claves de API, vigentes o revocadas, de la más antigua a la más reciente.
+func (service *MongoDBService) ListAPIKeys(operationContext context.Context) ([]models.APIKey, error) {
+	defer metrics.ObserveMongoOperation("list_api_keys", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "list_api_keys", service.apiKeysCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	apiKeysCursor, findErr := service.apiKeysCollection.Find(ctx, bson.M{}, findOptions)
+	if findErr != nil {
+		return nil, fmt.Errorf("error al consultar las claves de API en MongoDB: %w", findErr)
+	}
+	defer apiKeysCursor.Close(ctx)
+
+	apiKeys := []models.APIKey{}
+	if decodeErr := apiKeysCursor.All(ctx, &apiKeys); decodeErr != nil {
+		return nil, fmt.Errorf("error al decodificar las claves de API de MongoDB: %w", decodeErr)
+	}
+	return apiKeys, nil
+}
+
+// GetAPIKey obtiene la clave de API con el ID indicado.
+// Retorna nil y nil si no existe.
+func (service *MongoDBService) GetAPIKey(operationContext context.Context, apiKeyID string) (*models.APIKey, error) {
+	return service.findAPIKey(operationContext, bson.M{"_id": apiKeyID})
+}
+
+// GetAPIKeyByHash obtiene la clave de API con el hash indicado.
+// Retorna nil y nil si no existe.
+func (service *MongoDBService) GetAPIKeyByHash(operationContext context.Context, keyHash string) (*models.APIKey, error) {
+	return service.findAPIKey(operationContext, bson.M{"key_hash": keyHash})
+}
+
+// findAPIKey obtiene la primera clave de API que cumpla el filtro, o nil si no hay ninguna.
+func (service *MongoDBService) findAPIKey(operationContext context.Context, apiKeyFilter bson.M) (*models.APIKey, error) {
+	defer metrics.ObserveMongoOperation("find_api_key", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "find_api_key", service.apiKeysCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	var apiKey models.APIKey
+	decodeErr := service.apiKeysCollection.FindOne(ctx, apiKeyFilter).Decode(&apiKey)
 	if decodeErr != nil {
 		if decodeErr == mongo.ErrNoDocuments {
-			return 0, nil // No se encontraron documentos, retorna 0 y sin error.
+			return nil, nil
 		}
-		return 0, fmt.Errorf("error al obtener la última BCVRate de MongoDB: %w", decodeErr)
-	}
-	return latestBCVRecord.Value, nil
-}
-
-// GetBCVRateForToday obtiene la tasa BCV para el día actual.
-// Retorna 0.0 y nil si no se encuentra un registro para hoy.
-func (service *MongoDBService) GetBCVRateForToday() (float64, error) { 
-    var bcvTodayRecord models.BCVRate 
-    
-    // Crea un nuevo contexto con un timeout para esta operación específica.
-    // Puedes ajustar la duración (ej. 5*time.Second, 10*time.Second) según la latencia de tu DB.
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) 
-    defer cancel() // Es crucial llamar a cancel para liberar recursos del contexto.
-
-    // Asegura que la fecha esté en la zona horaria local para una comparación precisa del día.
-    // Sin embargo, para MongoDB, es mejor trabajar con UTC para las consultas.
-    // Convertir startOfCurrentDay y endOfCurrentDay a UTC.
-    currentTime := time.Now().In(time.Local) 
-    
-    // Define el inicio y fin del día actual en la zona horaria local,
-    // y luego convertirlos a UTC para la consulta de MongoDB.
-    startOfCurrentDayLocal := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, currentTime.Location()) 
-    endOfCurrentDayLocal := startOfCurrentDayLocal.Add(24*time.Hour).Add(-time.Nanosecond)
-
-    // Convertir a UTC para el filtro de la base de datos
-    startOfCurrentDayUTC := startOfCurrentDayLocal.UTC()
-    endOfCurrentDayUTC := endOfCurrentDayLocal.UTC()
-
-
-    // Crea el filtro para buscar documentos dentro del rango del día actual en UTC.
-    dayFilter := bson.M{
-        "timestamp": bson.M{
-            "$gte": startOfCurrentDayUTC, // Usar UTC
-            "$lte": endOfCurrentDayUTC,   // Usar UTC
-        },
-    }
-    
-    // Ordena por timestamp descendente para obtener el valor más reciente si hay múltiples para el mismo día.
-    findOptions := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}}) 
-
-    // Pasa el nuevo contexto 'ctx' a la operación de MongoDB.
-    decodeErr := service.collection.FindOne(ctx, dayFilter, findOptions).Decode(&bcvTodayRecord) 
-    if decodeErr != nil {
-        if decodeErr == mongo.ErrNoDocuments {
-            return 0, nil // No hay un registro para hoy, retorna 0 y sin error.
-        }
-        return 0, fmt.Errorf("error al obtener BCVRate para hoy de MongoDB: %w", decodeErr)
-    }
-    return bcvTodayRecord.Value, nil
-}
\ No newline at end of file
+		return nil, fmt.Errorf("error al obtener la clave de API de MongoDB: %w", decodeErr)
+	}
+	return &apiKey, nil
+}
+
+// SaveAPIKey crea o reemplaza la clave de API con su ID.
+func (service *MongoDBService) SaveAPIKey(operationContext context.Context, apiKey models.APIKey) error {
+	defer metrics.ObserveMongoOperation("save_api_key", time.Now())
+	operationContext, operationSpan := startMongoSpan(operationContext, "save_api_key", service.apiKeysCollection.Name())
+	defer operationSpan.End()
+	ctx, cancel := context.WithTimeout(operationContext, 10*time.Second) // 10 segundos de timeout
+	defer cancel()
+
+	apiKey.CreatedAt = apiKey.CreatedAt.UTC()
+	_, replaceErr := service.apiKeysCollection.ReplaceOne(ctx, bson.M{"_id": apiKey.ID}, apiKey, options.Replace().SetUpsert(true))
+	if replaceErr != nil {
+		return fmt.Errorf("error al guardar la clave de API '%s' en MongoDB: %w", apiKey.ID, replaceErr)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/metrics"
+)
+
+// NotificationKind indica qué tipo de mensaje se envía, para que cada canal lo presente según corresponda
+// (por ejemplo, el asunto de un correo).
+type NotificationKind string
+
+const (
+	// NotificationAlert es una alerta operativa: fallas al obtener o guardar la tasa, tasas en cuarentena...
+	NotificationAlert NotificationKind = "alert"
+	// NotificationRateChange es el aviso a los suscriptores de que se publicó una tasa distinta a la anterior.
+	NotificationRateChange NotificationKind = "rate_change"
+	// NotificationLargeMove es un cambio de tasa que alcanza el umbral de "movimiento grande".
+	NotificationLargeMove NotificationKind = "large_move"
+)
+
+// Subject retorna el título del mensaje para los canales que lo usan, como el asunto de un correo.
+func (kind NotificationKind) Subject() string {
+	switch kind {
+	case NotificationRateChange:
+		return "Nueva tasa BCV"
+	case NotificationLargeMove:
+		return "Movimiento grande en la tasa BCV"
+	default:
+		return "Alerta precio-bcv"
+	}
+}
+
+// Notifier es un canal capaz de enviar mensajes de alerta (WhatsApp, Telegram, correo, webhooks...).
+type Notifier interface {
+	Name() string
+	SendAlert(alertContext context.Context, kind NotificationKind, message string) error
+}
+
+// NotificationDispatcher reparte cada alerta entre todos los canales habilitados.
+// Implementa Notifier, por lo que puede usarse en cualquier lugar que espere un solo canal.
+type NotificationDispatcher struct {
+	notifiers []Notifier
+}
+
+// NewNotificationDispatcher crea un dispatcher con los canales habilitados en la configuración.
+// Un canal se considera habilitado cuando todas sus variables requeridas tienen valor.
+func NewNotificationDispatcher(appConfig *config.Config) *NotificationDispatcher {
+	enabledNotifiers := []Notifier{}
+
+	if appConfig.WhatsAppAPIURL != "" && appConfig.WhatsAppToNumber != "" {
+		enabledNotifiers = append(enabledNotifiers, NewWhatsAppService(appConfig))
+	}
+	if appConfig.TelegramBotToken != "" && appConfig.TelegramChatID != "" {
+		enabledNotifiers = append(enabledNotifiers, NewTelegramNotifier(appConfig))
+	}
+	if appConfig.SMTPHost != "" && appConfig.SMTPFrom != "" && len(appConfig.SMTPTo) > 0 {
+		enabledNotifiers = append(enabledNotifiers, NewEmailNotifier(appConfig))
+	}
+	if appConfig.SlackWebhookURL != "" {
+		enabledNotifiers = append(enabledNotifiers, NewSlackNotifier(appConfig))
+	}
+	if appConfig.AlertWebhookURL != "" {
+		enabledNotifiers = append(enabledNotifiers, NewWebhookNotifier(appConfig))
+	}
+
+	return NewNotificationDispatcherWith(enabledNotifiers...)
+}
+
+// NewRateSubscriberDispatcher crea un dispatcher con los canales de los suscriptores de cambios de tasa:
+// un WhatsApp por cada número en RATE_SUBSCRIBER_WHATSAPP_NUMBERS y el webhook RATE_SUBSCRIBER_WEBHOOK_URL.
+func NewRateSubscriberDispatcher(appConfig *config.Config) *NotificationDispatcher {
+	subscriberNotifiers := []Notifier{}
+
+	if appConfig.WhatsAppAPIURL != "" {
+		for _, subscriberNumber := range appConfig.RateSubscriberWhatsAppNumbers {
+			subscriberNotifiers = append(subscriberNotifiers, NewWhatsAppServiceTo(appConfig, subscriberNumber))
+		}
+	}
+	if appConfig.RateSubscriberWebhookURL != "" {
+		subscriberNotifiers = append(subscriberNotifiers, NewWebhookNotifierTo(appConfig.RateSubscriberWebhookURL))
+	}
+
+	return NewNotificationDispatcherWith(subscriberNotifiers...)
+}
+
+// NewNotificationDispatcherWith crea un dispatcher con los canales indicados.
+func NewNotificationDispatcherWith(notifiers ...Notifier) *NotificationDispatcher {
+	notifierNames := make([]string, 0, len(notifiers))
+	for _, notifier := range notifiers {
+		notifierNames = append(notifierNames, notifier.Name())
+	}
+	if len(notifierNames) == 0 {
+		slog.Warn("Dispatcher sin canales configurados. Sus mensajes solo se registrarán en el log.")
+	} else {
+		slog.Info("Dispatcher con canales habilitados", "channels", notifierNames)
+	}
+	return &NotificationDispatcher{notifiers: notifiers}
+}
+
+// Name retorna el nombre del dispatcher.
+func (dispatcher *NotificationDispatcher) Name() string {
+	return "dispatcher"
+}
+
+// SendAlert envía el mensaje a todos los canales en paralelo y espera a que terminen.
+// Un canal que falla no impide el envío por los demás; los errores se retornan combinados.
+func (dispatcher *NotificationDispatcher) SendAlert(alertContext context.Context, kind NotificationKind, message string) error {
+	if len(dispatcher.notifiers) == 0 {
+		slog.InfoContext(alertContext, "Mensaje sin canales configurados", "kind", kind, "message", message)
+		return nil
+	}
+
+	var sendWaitGroup sync.WaitGroup
+	sendErrors := make([]error, len(dispatcher.notifiers))
+	for notifierIndex, notifier := range dispatcher.notifiers {
+		sendWaitGroup.Add(1)
+		go func(notifierIndex int, notifier Notifier) {
+			defer sendWaitGroup.Done()
+			sendErr := notifier.SendAlert(alertContext, kind, message)
+			metrics.NotificationsSent.WithLabelValues(notifier.Name(), metrics.Outcome(sendErr)).Inc()
+			if sendErr != nil {
+				sendErrors[notifierIndex] = fmt.Errorf("%s: %w", notifier.Name(), sendErr)
+			}
+		}(notifierIndex, notifier)
+	}
+	sendWaitGroup.Wait()
+
+	return errors.Join(sendErrors...)
+}
+
+// maxErrorResponseBytes limita cuánto de la respuesta de error de un canal se lee para incluirlo en el error.
+const maxErrorResponseBytes = 4096
+
+// postJSON envía 'payload' serializado como JSON a 'targetURL' y verifica que la respuesta sea 2xx.
+// Es compartido por los canales basados en HTTP (Telegram, Slack y webhooks genéricos).
+func postJSON(requestContext context.Context, httpClient *http.Client, targetURL string, payload any) error {
+	requestBody, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return fmt.Errorf("error al serializar cuerpo de la solicitud: %w", marshalErr)
+	}
+
+	postRequest, requestErr := http.NewRequestWithContext(requestContext, http.MethodPost, targetURL, bytes.NewBuffer(requestBody))
+	if requestErr != nil {
+		return fmt.Errorf("error al crear solicitud: %w", requestErr)
+	}
+	postRequest.Header.Set("Content-Type", "application/json")
+
+	resp, postErr := httpClient.Do(postRequest)
+	if postErr != nil {
+		// La URL puede llevar credenciales (el token del bot de Telegram, la ruta secreta de un webhook de Slack)
+		// y el error termina en los logs, por lo que se descarta la URL del *url.Error.
+		var urlErr *url.Error
+		if errors.As(postErr, &urlErr) {
+			postErr = fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+		}
+		return fmt.Errorf("error al enviar solicitud: %w", postErr)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
+		return fmt.Errorf("código de estado: %d, respuesta: %s", resp.StatusCode, string(responseBody))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTelegramNotifierRedactsToken(t *testing.T) {
+	const botToken = "123456:secreto-del-bot"
+	// Un puerto cerrado produce un *url.Error, que incluye la URL con el token.
+	closedListener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+	closedAddress := closedListener.Addr().String()
+	closedListener.Close()
+
+	notifier := &TelegramNotifier{apiBaseURL: "http://" + closedAddress, botToken: botToken, chatID: "1", client: &http.Client{Timeout: time.Second}}
+	sendErr := notifier.SendAlert(context.Background(), NotificationAlert, "prueba")
+	if sendErr == nil {
+		t.Fatal("SendAlert no retornó un error")
+	}
+	if strings.Contains(sendErr.Error(), botToken) {
+		t.Fatalf("el error incluye el token del bot: %v", sendErr)
+	}
+}
+
+func TestPostJSONLimitsErrorBody(t *testing.T) {
+	errorServer := newStaticServer(t, http.StatusInternalServerError, "text/plain", []byte(strings.Repeat("x", 10*maxErrorResponseBytes)))
+	postErr := postJSON(context.Background(), http.DefaultClient, errorServer.URL, map[string]string{})
+	if postErr == nil {
+		t.Fatal("postJSON no retornó un error")
+	}
+	if len(postErr.Error()) > maxErrorResponseBytes+100 {
+		t.Fatalf("error de %d bytes; se esperaba uno de a lo sumo ~%d", len(postErr.Error()), maxErrorResponseBytes)
+	}
+}
+
+func TestWhatsAppServiceLimitsErrorBody(t *testing.T) {
+	errorServer := newStaticServer(t, http.StatusInternalServerError, "text/plain", []byte(strings.Repeat("x", 10*maxErrorResponseBytes)))
+	whatsAppService := &WhatsAppService{apiURL: errorServer.URL, toNumber: "584120000000", client: http.DefaultClient}
+	sendErr := whatsAppService.SendAlert(context.Background(), NotificationAlert, "prueba")
+	if sendErr == nil {
+		t.Fatal("SendAlert no retornó un error")
+	}
+	if len(sendErr.Error()) > maxErrorResponseBytes+200 {
+		t.Fatalf("error de %d bytes; se esperaba uno de a lo sumo ~%d", len(sendErr.Error()), maxErrorResponseBytes)
+	}
+}
+
+// startFakeSMTPServer atiende una sesión SMTP mínima por conexión y envía a 'receivedMessages' cada mensaje recibido.
+// Si 'silent' es true, acepta las conexiones pero nunca responde.
+func startFakeSMTPServer(t *testing.T, silent bool, receivedMessages chan<- string) string {
+	t.Helper()
+	smtpListener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+	t.Cleanup(func() { smtpListener.Close() })
+
+	go func() {
+		for {
+			smtpConnection, acceptErr := smtpListener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go func(smtpConnection net.Conn) {
+				defer smtpConnection.Close()
+				if silent {
+					smtpConnection.Read(make([]byte, 1)) // Espera a que el cliente cierre la conexión.
+					return
+				}
+				connectionReader := bufio.NewReader(smtpConnection)
+				reply := func(replyLine string) { smtpConnection.Write([]byte(replyLine + "\r\n")) }
+				reply("220 localhost ESMTP")
+				var messageBuilder strings.Builder
+				readingData := false
+				for {
+					commandLine, readErr := connectionReader.ReadString('\n')
+					if readErr != nil {
+						return
+					}
+					if readingData {
+						if commandLine == ".\r\n" {
+							readingData = false
+							receivedMessages <- messageBuilder.String()
+							reply("250 OK")
+							continue
+						}
+						messageBuilder.WriteString(commandLine)
+						continue
+					}
+					switch command := strings.ToUpper(strings.Fields(commandLine + " ")[0]); command {
+					case "EHLO", "HELO", "MAIL", "RCPT":
+						reply("250 OK")
+					case "DATA":
+						readingData = true
+						reply("354 Continúe")
+					case "QUIT":
+						reply("221 Adiós")
+						return
+					default:
+						reply("502 No implementado")
+					}
+				}
+			}(smtpConnection)
+		}
+	}()
+	return smtpListener.Addr().String()
+}
+
+// newTestEmailNotifier crea un canal de correo sin autenticación hacia 'serverAddress'.
+func newTestEmailNotifier(serverAddress string) *EmailNotifier {
+	return &EmailNotifier{serverAddress: serverAddress, host: "localhost", fromAddress: "alertas@example.com", toAddresses: []string{"ops@example.com"}}
+}
+
+func TestEmailNotifierSendsMessage(t *testing.T) {
+	receivedMessages := make(chan string, 1)
+	serverAddress := startFakeSMTPServer(t, false, receivedMessages)
+
+	if sendErr := newTestEmailNotifier(serverAddress).SendAlert(context.Background(), NotificationRateChange, "La tasa cambió"); sendErr != nil {
+		t.Fatalf("SendAlert retornó un error: %v", sendErr)
+	}
+	receivedMessage := <-receivedMessages
+	if !strings.Contains(receivedMessage, "To: ops@example.com") || !strings.Contains(receivedMessage, "Subject: Nueva tasa BCV") || !strings.Contains(receivedMessage, "La tasa cambió") {
+		t.Fatalf("mensaje recibido:\n%s", receivedMessage)
+	}
+}
+
+func TestEmailNotifierRespectsContext(t *testing.T) {
+	serverAddress := startFakeSMTPServer(t, true, nil)
+	sendContext, cancelSend := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancelSend()
+
+	sendStart := time.Now()
+	if sendErr := newTestEmailNotifier(serverAddress).SendAlert(sendContext, NotificationAlert, "prueba"); sendErr == nil {
+		t.Fatal("SendAlert no retornó un error con un servidor que no responde")
+	}
+	if elapsed := time.Since(sendStart); elapsed > 2*time.Second {
+		t.Fatalf("SendAlert tardó %s; se esperaba que terminara al vencer el contexto", elapsed)
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"precio-bcv-go/models"
+)
+
+// PlanRepository define las operaciones de almacenamiento del catálogo de planes.
+type PlanRepository interface {
+	ListPlans(operationContext context.Context) ([]models.Plan, error)
+	GetPlan(operationContext context.Context, planID string) (*models.Plan, error)
+	SavePlan(operationContext context.Context, plan models.Plan) error
+	DeletePlan(operationContext context.Context, planID string) (bool, error)
+}
+
+// Verificaciones en tiempo de compilación de que cada backend implementa PlanRepository.
+var (
+	_ PlanRepository = (*MongoDBService)(nil)
+	_ PlanRepository = (*MemoryPlanRepository)(nil)
+	_ PlanRepository = (*BoltPlanRepository)(nil)
+)
+
+// NewPlanRepository retorna el repositorio de planes que comparte el backend del repositorio de tasas,
+// para que ambos catálogos vivan en la misma base de datos.
+func NewPlanRepository(rateRepository RateRepository) (PlanRepository, error) {
+	switch typedRepository := rateRepository.(type) {
+	case *MongoDBService:
+		return typedRepository, nil
+	case *BoltRateRepository:
+		return NewBoltPlanRepository(typedRepository.database)
+	case *MemoryRateRepository:
+		return NewMemoryPlanRepository(), nil
+	default:
+		return nil, fmt.Errorf("el backend de almacenamiento %T no soporta el catálogo de planes", rateRepository)
+	}
+}
+
+// sortPlans ordena los planes por precio en USD y luego por ID, para un listado estable.
+func sortPlans(plans []models.Plan) {
+	sort.Slice(plans, func(i, j int) bool {
+		if priceOrder := plans[i].PriceUSD.Cmp(plans[j].PriceUSD); priceOrder != 0 {
+			return priceOrder < 0
+		}
+		return plans[i].ID < plans[j].ID
+	})
+}
+
+// MemoryPlanRepository guarda el catálogo de planes en memoria.
+type MemoryPlanRepository struct {
+	plansMutex sync.RWMutex
+	plans      map[string]models.Plan
+}
+
+// NewMemoryPlanRepository crea un catálogo de planes vacío en memoria.
+func NewMemoryPlanRepository() *MemoryPlanRepository {
+	return &MemoryPlanRepository{plans: map[string]models.Plan{}}
+}
+
+// ListPlans retorna todos los planes, activos o no.
+func (repository *MemoryPlanRepository) ListPlans(operationContext context.Context) ([]models.Plan, error) {
+	repository.plansMutex.RLock()
+	defer repository.plansMutex.RUnlock()
+
+	plans := make([]models.Plan, 0, len(repository.plans))
+	for _, plan := range repository.plans {
+		plans = append(plans, plan)
+	}
+	sortPlans(plans)
+	return plans, nil
+}
+
+// GetPlan retorna el plan con el ID indicado, o nil si no existe.
+func (repository *MemoryPlanRepository) GetPlan(operationContext context.Context, planID string) (*models.Plan, error) {
+	repository.plansMutex.RLock()
+	defer repository.plansMutex.RUnlock()
+
+	plan, exists := repository.plans[planID]
+	if !exists {
+		return nil, nil
+	}
+	return &plan, nil
+}
+
+// SavePlan crea o reemplaza el plan con su ID.
+func (repository *MemoryPlanRepository) SavePlan(operationContext context.Context, plan models.Plan) error {
+	repository.plansMutex.Lock()
+	defer repository.plansMutex.Unlock()
+	repository.plans[plan.ID] = plan
+	return nil
+}
+
+// DeletePlan elimina el plan indicado. Retorna false si no existía.
+func (repository *MemoryPlanRepository) DeletePlan(operationContext context.Context, planID string) (bool, error) {
+	repository.plansMutex.Lock()
+	defer repository.plansMutex.Unlock()
+
+	_, exists := repository.plans[planID]
+	delete(repository.plans, planID)
+	return exists, nil
+}
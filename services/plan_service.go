@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Errores retornados por PlanService, para que los manejadores HTTP elijan el código de estado.
+var (
+	ErrPlanNotFound = errors.New("plan no encontrado")
+	ErrPlanExists   = errors.New("ya existe un plan con ese ID")
+	ErrInvalidPlan  = errors.New("plan inválido")
+)
+
+// planIDPattern restringe los IDs de plan a minúsculas, dígitos, guiones y guiones bajos (ej. "plan-20").
+var planIDPattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// defaultPlans es el catálogo inicial cuando no hay planes guardados ni archivo PLANS_FILE.
+var defaultPlans = []models.Plan{
+	{ID: "plan-20", Name: "Plan 20", PriceUSD: decimal.NewFromInt(20), TaxProfile: "iva_8", Active: true},
+	{ID: "plan-25", Name: "Plan 25", PriceUSD: decimal.NewFromInt(25), TaxProfile: "iva_8", Active: true},
+	{ID: "plan-30", Name: "Plan 30", PriceUSD: decimal.NewFromInt(30), TaxProfile: "iva_8", Active: true},
+}
+
+// PlanService administra el catálogo de planes: validación, altas, cambios y bajas.
+type PlanService struct {
+	planRepository PlanRepository
+	taxEngine      *TaxEngine // Para validar que el perfil de impuestos de cada plan exista.
+}
+
+// NewPlanService crea el servicio del catálogo de planes. Si el catálogo está vacío,
+// lo inicializa desde el archivo PLANS_FILE o, si no está configurado, con los planes por defecto.
+func NewPlanService(startupContext context.Context, appConfig *config.Config, planRepository PlanRepository, taxEngine *TaxEngine) (*PlanService, error) {
+	planService := &PlanService{planRepository: planRepository, taxEngine: taxEngine}
+
+	storedPlans, listErr := planRepository.ListPlans(startupContext)
+	if listErr != nil {
+		return nil, listErr
+	}
+	if len(storedPlans) > 0 {
+		slog.Info("Catálogo de planes cargado", "plans", len(storedPlans))
+		return planService, nil
+	}
+
+	seedPlans := defaultPlans
+	if appConfig.PlansFile != "" {
+		filePlans, loadErr := loadPlansFile(appConfig.PlansFile)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		seedPlans = filePlans
+	}
+
+	for _, seedPlan := range seedPlans {
+		if _, createErr := planService.CreatePlan(startupContext, seedPlan); createErr != nil {
+			return nil, fmt.Errorf("error al inicializar el catálogo de planes: %w", createErr)
+		}
+	}
+	slog.Info("Catálogo de planes inicializado", "plans", len(seedPlans))
+	return planService, nil
+}
+
+// loadPlansFile lee un archivo JSON con un arreglo de planes.
+func loadPlansFile(plansFilePath string) ([]models.Plan, error) {
+	fileContent, readErr := os.ReadFile(plansFilePath)
+	if readErr != nil {
+		return nil, fmt.Errorf("error al leer el archivo de planes '%s': %w", plansFilePath, readErr)
+	}
+	var filePlans []models.Plan
+	if unmarshalErr := json.Unmarshal(fileContent, &filePlans); unmarshalErr != nil {
+		return nil, fmt.Errorf("error al interpretar el archivo de planes '%s': %w", plansFilePath, unmarshalErr)
+	}
+	return filePlans, nil
+}
+
+// ListPlans retorna todos los planes del catálogo, activos o no.
+func (service *PlanService) ListPlans(requestContext context.Context) ([]models.Plan, error) {
+	return service.planRepository.ListPlans(requestContext)
+}
+
+// ListActivePlans retorna solo los planes activos del catálogo.
+func (service *PlanService) ListActivePlans(requestContext context.Context) ([]models.Plan, error) {
+	allPlans, listErr := service.planRepository.ListPlans(requestContext)
+	if listErr != nil {
+		return nil, listErr
+	}
+	activePlans := []models.Plan{}
+	for _, plan := range allPlans {
+		if plan.Active {
+			activePlans = append(activePlans, plan)
+		}
+	}
+	return activePlans, nil
+}
+
+// GetPlan retorna el plan indicado, o ErrPlanNotFound si no existe.
+func (service *PlanService) GetPlan(requestContext context.Context, planID string) (*models.Plan, error) {
+	plan, getErr := service.planRepository.GetPlan(requestContext, planID)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+// CreatePlan valida y agrega un plan nuevo, retornándolo tal como quedó guardado.
+// Retorna ErrPlanExists si el ID ya está en uso.
+func (service *PlanService) CreatePlan(requestContext context.Context, plan models.Plan) (models.Plan, error) {
+	if validationErr := service.validatePlan(&plan); validationErr != nil {
+		return models.Plan{}, validationErr
+	}
+	existingPlan, getErr := service.planRepository.GetPlan(requestContext, plan.ID)
+	if getErr != nil {
+		return models.Plan{}, getErr
+	}
+	if existingPlan != nil {
+		return models.Plan{}, ErrPlanExists
+	}
+	return plan, service.planRepository.SavePlan(requestContext, plan)
+}
+
+// UpdatePlan valida y reemplaza un plan existente, retornándolo tal como quedó guardado.
+// Retorna ErrPlanNotFound si no existe.
+func (service *PlanService) UpdatePlan(requestContext context.Context, plan models.Plan) (models.Plan, error) {
+	if validationErr := service.validatePlan(&plan); validationErr != nil {
+		return models.Plan{}, validationErr
+	}
+	if _, getErr := service.GetPlan(requestContext, plan.ID); getErr != nil {
+		return models.Plan{}, getErr
+	}
+	return plan, service.planRepository.SavePlan(requestContext, plan)
+}
+
+// DeletePlan elimina un plan. Retorna ErrPlanNotFound si no existe.
+func (service *PlanService) DeletePlan(requestContext context.Context, planID string) error {
+	planExisted, deleteErr := service.planRepository.DeletePlan(requestContext, planID)
+	if deleteErr != nil {
+		return deleteErr
+	}
+	if !planExisted {
+		return ErrPlanNotFound
+	}
+	return nil
+}
+
+// validatePlan normaliza y valida los campos de un plan. Los errores envuelven ErrInvalidPlan.
+// Un plan sin perfil de impuestos usa DefaultTaxProfile. El perfil debe poder calcularse hoy: no basta
+// con que exista, también debe tener una versión vigente.
+func (service *PlanService) validatePlan(plan *models.Plan) error {
+	plan.ID = strings.TrimSpace(plan.ID)
+	plan.Name = strings.TrimSpace(plan.Name)
+	plan.TaxProfile = strings.TrimSpace(plan.TaxProfile)
+
+	if !planIDPattern.MatchString(plan.ID) {
+		return fmt.Errorf("%w: el ID '%s' debe tener solo minúsculas, dígitos, '-' o '_'", ErrInvalidPlan, plan.ID)
+	}
+	if plan.Name == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrInvalidPlan)
+	}
+	if !plan.PriceUSD.IsPositive() {
+		return fmt.Errorf("%w: el precio en USD debe ser mayor que 0", ErrInvalidPlan)
+	}
+	if plan.TaxProfile == "" {
+		plan.TaxProfile = DefaultTaxProfile
+	}
+	if _, taxErr := service.taxEngine.Calculate(plan.TaxProfile, decimal.Zero, time.Now()); taxErr != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPlan, taxErr)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"precio-bcv-go/models"
+)
+
+// QuarantineRepository define las operaciones de almacenamiento de las tasas en cuarentena.
+type QuarantineRepository interface {
+	ListQuarantinedRates(operationContext context.Context) ([]models.QuarantinedRate, error)
+	GetQuarantinedRate(operationContext context.Context, quarantineID string) (*models.QuarantinedRate, error)
+	SaveQuarantinedRate(operationContext context.Context, quarantinedRate models.QuarantinedRate) error
+	SaveQuarantinedRateIfStatus(operationContext context.Context, quarantinedRate models.QuarantinedRate, expectedStatus string) (bool, error)
+}
+
+// Verificaciones en tiempo de compilación de que cada backend implementa QuarantineRepository.
+var (
+	_ QuarantineRepository = (*MongoDBService)(nil)
+	_ QuarantineRepository = (*MemoryQuarantineRepository)(nil)
+	_ QuarantineRepository = (*BoltQuarantineRepository)(nil)
+)
+
+// NewQuarantineRepository retorna el repositorio de cuarentena que comparte el backend del repositorio de tasas.
+func NewQuarantineRepository(rateRepository RateRepository) (QuarantineRepository, error) {
+	switch typedRepository := rateRepository.(type) {
+	case *MongoDBService:
+		return typedRepository, nil
+	case *BoltRateRepository:
+		return NewBoltQuarantineRepository(typedRepository.database)
+	case *MemoryRateRepository:
+		return NewMemoryQuarantineRepository(), nil
+	default:
+		return nil, fmt.Errorf("el backend de almacenamiento %T no soporta la cuarentena de tasas", rateRepository)
+	}
+}
+
+// sortQuarantinedRates ordena las tasas en cuarentena de la más reciente a la más antigua.
+func sortQuarantinedRates(quarantinedRates []models.QuarantinedRate) {
+	sort.Slice(quarantinedRates, func(i, j int) bool {
+		return quarantinedRates[i].QuarantinedAt.After(quarantinedRates[j].QuarantinedAt)
+	})
+}
+
+// MemoryQuarantineRepository guarda las tasas en cuarentena en memoria.
+type MemoryQuarantineRepository struct {
+	quarantineMutex  sync.RWMutex
+	quarantinedRates map[string]models.QuarantinedRate
+}
+
+// NewMemoryQuarantineRepository crea una cuarentena vacía en memoria.
+func NewMemoryQuarantineRepository() *MemoryQuarantineRepository {
+	return &MemoryQuarantineRepository{quarantinedRates: map[string]models.QuarantinedRate{}}
+}
+
+// ListQuarantinedRates retorna todas las tasas en cuarentena, revisadas o no.
+func (repository *MemoryQuarantineRepository) ListQuarantinedRates(operationContext context.Context) ([]models.QuarantinedRate, error) {
+	repository.quarantineMutex.RLock()
+	defer repository.quarantineMutex.RUnlock()
+
+	quarantinedRates := make([]models.QuarantinedRate, 0, len(repository.quarantinedRates))
+	for _, quarantinedRate := range repository.quarantinedRates {
+		quarantinedRates = append(quarantinedRates, quarantinedRate)
+	}
+	sortQuarantinedRates(quarantinedRates)
+	return quarantinedRates, nil
+}
+
+// GetQuarantinedRate retorna la tasa en cuarentena con el ID indicado, o nil si no existe.
+func (repository *MemoryQuarantineRepository) GetQuarantinedRate(operationContext context.Context, quarantineID string) (*models.QuarantinedRate, error) {
+	repository.quarantineMutex.RLock()
+	defer repository.quarantineMutex.RUnlock()
+
+	quarantinedRate, exists := repository.quarantinedRates[quarantineID]
+	if !exists {
+		return nil, nil
+	}
+	return &quarantinedRate, nil
+}
+
+// SaveQuarantinedRate crea o reemplaza la tasa en cuarentena con su ID.
+func (repository *MemoryQuarantineRepository) SaveQuarantinedRate(operationContext context.Context, quarantinedRate models.QuarantinedRate) error {
+	repository.quarantineMutex.Lock()
+	defer repository.quarantineMutex.Unlock()
+	repository.quarantinedRates[quarantinedRate.ID] = quarantinedRate
+	return nil
+}
+
+// SaveQuarantinedRateIfStatus reemplaza la tasa en cuarentena solo si su estado guardado es 'expectedStatus'.
+// Retorna false si no existe o tiene otro estado (ej. otra petición ya la revisó).
+func (repository *MemoryQuarantineRepository) SaveQuarantinedRateIfStatus(operationContext context.Context, quarantinedRate models.QuarantinedRate, expectedStatus string) (bool, error) {
+	repository.quarantineMutex.Lock()
+	defer repository.quarantineMutex.Unlock()
+
+	storedRate, exists := repository.quarantinedRates[quarantinedRate.ID]
+	if !exists || storedRate.Status != expectedStatus {
+		return false, nil
+	}
+	repository.quarantinedRates[quarantinedRate.ID] = quarantinedRate
+	return true, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateChange describe la variación de una moneda entre la tasa anterior y la recién publicada.
+type RateChange struct {
+	Currency      string
+	PreviousRate  decimal.Decimal
+	NewRate       decimal.Decimal
+	ChangePercent float64 // Solo informativo, para mensajes y umbrales; por eso basta un float64.
+}
+
+// compareRates calcula la variación de cada moneda presente en ambas publicaciones.
+// Las monedas sin cambio se omiten; el resultado está ordenado con USD primero y luego alfabéticamente.
+func compareRates(previousRates, newRates map[string]decimal.Decimal) []RateChange {
+	rateChanges := []RateChange{}
+	for currency, newRate := range newRates {
+		previousRate, existed := previousRates[currency]
+		if !existed || !previousRate.IsPositive() || previousRate.Equal(newRate) {
+			continue
+		}
+		rateChanges = append(rateChanges, RateChange{
+			Currency:      currency,
+			PreviousRate:  previousRate,
+			NewRate:       newRate,
+			ChangePercent: percentChange(previousRate, newRate),
+		})
+	}
+	sort.Slice(rateChanges, func(i, j int) bool {
+		if rateChanges[i].Currency == DefaultCurrency || rateChanges[j].Currency == DefaultCurrency {
+			return rateChanges[i].Currency == DefaultCurrency
+		}
+		return rateChanges[i].Currency < rateChanges[j].Currency
+	})
+	return rateChanges
+}
+
+// percentChange retorna la variación porcentual de 'previousRate' a 'newRate'; 'previousRate' debe ser mayor que 0.
+func percentChange(previousRate, newRate decimal.Decimal) float64 {
+	return newRate.Sub(previousRate).Div(previousRate).Shift(2).InexactFloat64()
+}
+
+// isLargeMove indica si alguna variación alcanza el umbral (en valor absoluto) de "movimiento grande".
+// Un umbral menor o igual a 0 desactiva la detección.
+func isLargeMove(rateChanges []RateChange, thresholdPercent float64) bool {
+	if thresholdPercent <= 0 {
+		return false
+	}
+	for _, rateChange := range rateChanges {
+		if math.Abs(rateChange.ChangePercent) >= thresholdPercent {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRateChangeMessage arma el mensaje para los suscriptores con el valor anterior,
+// el nuevo y la variación porcentual de cada moneda.
+func formatRateChangeMessage(rateChanges []RateChange, effectiveDate time.Time, largeMove bool) string {
+	var messageBuilder strings.Builder
+	if largeMove {
+		messageBuilder.WriteString("Alerta: Movimiento grande en la tasa BCV.\n")
+	}
+	fmt.Fprintf(&messageBuilder, "Nueva tasa BCV (fecha valor %s):", effectiveDate.Format("2006-01-02"))
+	for _, rateChange := range rateChanges {
+		fmt.Fprintf(&messageBuilder, "\n%s: %s -> %s (%+.2f%%)", rateChange.Currency, rateChange.PreviousRate, rateChange.NewRate, rateChange.ChangePercent)
+	}
+	return messageBuilder.String()
+}
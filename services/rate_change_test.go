@@ -0,0 +1,129 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateMap arma un mapa de tasas a partir de textos decimales (ej. "USD": "36.5").
+func rateMap(t *testing.T, rateTexts map[string]string) map[string]decimal.Decimal {
+	t.Helper()
+	rates := make(map[string]decimal.Decimal, len(rateTexts))
+	for currency, rateText := range rateTexts {
+		rates[currency] = decimal.RequireFromString(rateText)
+	}
+	return rates
+}
+
+func TestCompareRates(t *testing.T) {
+	testCases := []struct {
+		name               string
+		previousRates      map[string]string
+		newRates           map[string]string
+		expectedCurrencies []string
+	}{
+		{
+			name:               "USD primero y luego alfabético",
+			previousRates:      map[string]string{"EUR": "39", "CNY": "5", "USD": "36", "RUB": "0.4"},
+			newRates:           map[string]string{"EUR": "40", "CNY": "5.1", "USD": "37", "RUB": "0.41"},
+			expectedCurrencies: []string{"USD", "CNY", "EUR", "RUB"},
+		},
+		{
+			name:               "monedas sin cambio se omiten",
+			previousRates:      map[string]string{"USD": "36.10", "EUR": "39"},
+			newRates:           map[string]string{"USD": "36.1", "EUR": "39.5"},
+			expectedCurrencies: []string{"EUR"},
+		},
+		{
+			name:               "moneda sin tasa anterior se omite",
+			previousRates:      map[string]string{"USD": "36"},
+			newRates:           map[string]string{"USD": "36.5", "EUR": "39"},
+			expectedCurrencies: []string{"USD"},
+		},
+		{
+			name:               "tasa anterior en cero se omite",
+			previousRates:      map[string]string{"USD": "0", "EUR": "39"},
+			newRates:           map[string]string{"USD": "36", "EUR": "40"},
+			expectedCurrencies: []string{"EUR"},
+		},
+		{
+			name:               "sin tasas anteriores",
+			previousRates:      map[string]string{},
+			newRates:           map[string]string{"USD": "36"},
+			expectedCurrencies: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rateChanges := compareRates(rateMap(t, testCase.previousRates), rateMap(t, testCase.newRates))
+			if len(rateChanges) != len(testCase.expectedCurrencies) {
+				t.Fatalf("compareRates retornó %d cambios (%+v); se esperaban %v", len(rateChanges), rateChanges, testCase.expectedCurrencies)
+			}
+			for changeIndex, rateChange := range rateChanges {
+				expectedCurrency := testCase.expectedCurrencies[changeIndex]
+				if rateChange.Currency != expectedCurrency {
+					t.Fatalf("cambio %d = %s; se esperaba %s", changeIndex, rateChange.Currency, expectedCurrency)
+				}
+				if !rateChange.PreviousRate.Equal(decimal.RequireFromString(testCase.previousRates[expectedCurrency])) ||
+					!rateChange.NewRate.Equal(decimal.RequireFromString(testCase.newRates[expectedCurrency])) {
+					t.Fatalf("cambio de %s = %s -> %s; no coincide con las tasas de entrada", expectedCurrency, rateChange.PreviousRate, rateChange.NewRate)
+				}
+			}
+		})
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	testCases := []struct {
+		name         string
+		previousRate string
+		newRate      string
+		expected     float64
+	}{
+		{name: "subida", previousRate: "36", newRate: "37.8", expected: 5},
+		{name: "bajada", previousRate: "40", newRate: "38", expected: -5},
+		{name: "sin cambio", previousRate: "36.5", newRate: "36.5", expected: 0},
+		{name: "variación pequeña", previousRate: "36.12345678", newRate: "36.13", expected: 0.0181},
+		{name: "se duplica", previousRate: "0.5", newRate: "1", expected: 100},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			changePercent := percentChange(decimal.RequireFromString(testCase.previousRate), decimal.RequireFromString(testCase.newRate))
+			if math.Abs(changePercent-testCase.expected) > 0.0001 {
+				t.Fatalf("percentChange(%s, %s) = %v; se esperaba %v", testCase.previousRate, testCase.newRate, changePercent, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestIsLargeMove(t *testing.T) {
+	testCases := []struct {
+		name             string
+		changePercents   []float64
+		thresholdPercent float64
+		expected         bool
+	}{
+		{name: "umbral desactivado", changePercents: []float64{50}, thresholdPercent: 0, expected: false},
+		{name: "umbral negativo", changePercents: []float64{50}, thresholdPercent: -1, expected: false},
+		{name: "por debajo del umbral", changePercents: []float64{1.5, -2.9}, thresholdPercent: 3, expected: false},
+		{name: "justo en el umbral", changePercents: []float64{3}, thresholdPercent: 3, expected: true},
+		{name: "bajada grande", changePercents: []float64{0.5, -4}, thresholdPercent: 3, expected: true},
+		{name: "sin cambios", changePercents: nil, thresholdPercent: 3, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rateChanges := make([]RateChange, 0, len(testCase.changePercents))
+			for _, changePercent := range testCase.changePercents {
+				rateChanges = append(rateChanges, RateChange{Currency: DefaultCurrency, ChangePercent: changePercent})
+			}
+			if largeMove := isLargeMove(rateChanges, testCase.thresholdPercent); largeMove != testCase.expected {
+				t.Fatalf("isLargeMove(%v, %v) = %v; se esperaba %v", testCase.changePercents, testCase.thresholdPercent, largeMove, testCase.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateTokenPattern localiza los números dentro del texto del elemento de la tasa (ej. " 36,12345678 ").
+var rateTokenPattern = regexp.MustCompile(`\d[\d.,]*\d|\d`)
+
+// venezuelanNumberPattern es el formato venezolano: punto solo para agrupar miles y coma para los decimales
+// ("36,12345678", "1.234,56", "1.234"). Un punto seguido de un grupo que no tiene 3 dígitos ("36.12") no lo cumple.
+var venezuelanNumberPattern = regexp.MustCompile(`^(?:\d{1,3}(?:\.\d{3})+|\d+)(?:,\d+)?$`)
+
+// RateNotFoundError indica que no se encontró un número válido en el texto scrapeado.
+type RateNotFoundError struct {
+	Text string // Texto original del elemento, útil para diagnosticar cambios en la página.
+}
+
+func (notFoundErr *RateNotFoundError) Error() string {
+	return fmt.Sprintf("no se encontró una tasa válida en el texto scrapeado %q", strings.TrimSpace(notFoundErr.Text))
+}
+
+// ExtractRate convierte el texto del elemento que contiene la tasa (ej. el <strong> del bloque "#dolar")
+// en un decimal exacto. El texto debe tener un único número positivo en formato venezolano; los espacios
+// y cualquier texto no numérico alrededor se ignoran. Si no hay ningún número, si hay más de uno (la tasa
+// sería ambigua) o si no es válido, retorna un *RateNotFoundError.
+func ExtractRate(rateText string) (decimal.Decimal, error) {
+	rateTokens := rateTokenPattern.FindAllString(rateText, -1)
+	if len(rateTokens) != 1 {
+		return decimal.Zero, &RateNotFoundError{Text: rateText}
+	}
+	parsedRate, parseErr := ParseVenezuelanNumber(rateTokens[0])
+	if parseErr != nil || !parsedRate.IsPositive() {
+		return decimal.Zero, &RateNotFoundError{Text: rateText}
+	}
+	return parsedRate, nil
+}
+
+// ParseVenezuelanNumber convierte un número en formato venezolano (punto para miles y coma para decimales)
+// a un decimal exacto, conservando todos sus decimales. El punto siempre se interpreta como separador de miles,
+// sin importar la magnitud: "1.234" es 1234 igual que "1.234.567" es 1234567. Un texto con un punto que no
+// agrupa miles (ej. "36.12") se rechaza en lugar de adivinar su significado.
+func ParseVenezuelanNumber(numberText string) (decimal.Decimal, error) {
+	cleanedText := strings.TrimSpace(numberText)
+	if !venezuelanNumberPattern.MatchString(cleanedText) {
+		return decimal.Zero, fmt.Errorf("error al convertir '%s' a número: no tiene formato venezolano (ej. 1.234,56)", numberText)
+	}
+	cleanedText = strings.ReplaceAll(cleanedText, ".", "")
+	cleanedText = strings.ReplaceAll(cleanedText, ",", ".")
+
+	parsedNumber, parseErr := decimal.NewFromString(cleanedText)
+	if parseErr != nil {
+		return decimal.Zero, fmt.Errorf("error al convertir '%s' a número: %w", numberText, parseErr)
+	}
+	return parsedNumber, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVenezuelanNumber(t *testing.T) {
+	testCases := []struct {
+		name        string
+		numberText  string
+		expected    string
+		expectError bool
+	}{
+		{name: "coma decimal", numberText: "36,12345678", expected: "36.12345678"},
+		{name: "miles y decimales", numberText: "1.234,56", expected: "1234.56"},
+		{name: "varios grupos de miles", numberText: "1.234.567,89", expected: "1234567.89"},
+		{name: "entero", numberText: "36", expected: "36"},
+		{name: "espacios alrededor", numberText: "  0,39521380 ", expected: "0.3952138"},
+		// El punto siempre agrupa miles, sin importar la magnitud del número.
+		{name: "un grupo de miles sin decimales", numberText: "1.234", expected: "1234"},
+		{name: "dos grupos de miles sin decimales", numberText: "1.234.567", expected: "1234567"},
+		// Formatos ambiguos o con punto decimal: se rechazan en lugar de adivinar.
+		{name: "punto decimal", numberText: "36.12", expectError: true},
+		{name: "grupo de miles incompleto", numberText: "1.23,45", expectError: true},
+		{name: "grupo de miles demasiado largo", numberText: "1.2345,6", expectError: true},
+		{name: "primer grupo demasiado largo", numberText: "1234.567", expectError: true},
+		{name: "dos comas", numberText: "1,234,56", expectError: true},
+		{name: "coma final", numberText: "36,", expectError: true},
+		{name: "vacío", numberText: "", expectError: true},
+		{name: "texto", numberText: "N/D", expectError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parsedNumber, parseErr := ParseVenezuelanNumber(testCase.numberText)
+			if testCase.expectError {
+				if parseErr == nil {
+					t.Fatalf("ParseVenezuelanNumber(%q) = %s; se esperaba un error", testCase.numberText, parsedNumber)
+				}
+				return
+			}
+			if parseErr != nil {
+				t.Fatalf("ParseVenezuelanNumber(%q) retornó un error: %v", testCase.numberText, parseErr)
+			}
+			if parsedNumber.String() != testCase.expected {
+				t.Fatalf("ParseVenezuelanNumber(%q) = %s; se esperaba %s", testCase.numberText, parsedNumber, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestExtractRate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rateText string
+		expected string // Vacío si se espera un *RateNotFoundError.
+	}{
+		{name: "tasa con espacios", rateText: " 36,12345678 ", expected: "36.12345678"},
+		{name: "tasa en varias líneas", rateText: "\n\t 1.234,56789012\n ", expected: "1234.56789012"},
+		{name: "tasa con prefijo de moneda", rateText: "Bs. 36,12", expected: "36.12"},
+		{name: "sin número", rateText: "N/D", expected: ""},
+		{name: "vacío", rateText: "", expected: ""},
+		{name: "cero", rateText: "0,00", expected: ""},
+		{name: "dos números", rateText: "1,12 - 1,13", expected: ""},
+		{name: "fecha y tasa", rateText: "15/03/2024 36,12", expected: ""},
+		{name: "punto decimal", rateText: "36.12", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			extractedRate, extractErr := ExtractRate(testCase.rateText)
+			if testCase.expected == "" {
+				var notFoundErr *RateNotFoundError
+				if !errors.As(extractErr, &notFoundErr) {
+					t.Fatalf("ExtractRate(%q) = %s, %v; se esperaba un *RateNotFoundError", testCase.rateText, extractedRate, extractErr)
+				}
+				return
+			}
+			if extractErr != nil {
+				t.Fatalf("ExtractRate(%q) retornó un error: %v", testCase.rateText, extractErr)
+			}
+			if extractedRate.String() != testCase.expected {
+				t.Fatalf("ExtractRate(%q) = %s; se esperaba %s", testCase.rateText, extractedRate, testCase.expected)
+			}
+		})
+	}
+}
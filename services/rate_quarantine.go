@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Estados de una tasa en cuarentena.
+const (
+	QuarantinePending  = "pending"
+	QuarantineApproved = "approved"
+	QuarantineRejected = "rejected"
+)
+
+// Errores retornados al revisar tasas en cuarentena, para que los manejadores HTTP elijan el código de estado.
+var (
+	ErrQuarantinedRateNotFound = errors.New("tasa en cuarentena no encontrada")
+	ErrQuarantinedRateReviewed = errors.New("la tasa en cuarentena ya fue revisada")
+)
+
+// dailyChangeViolations lista las monedas cuya variación respecto al último registro guardado supera
+// la variación diaria máxima. Un límite menor o igual a 0 desactiva la verificación.
+func (service *BCVService) dailyChangeViolations(previousRecord *models.BCVRate, newRates map[string]decimal.Decimal) []string {
+	if service.maxDailyChangePercent <= 0 || previousRecord == nil {
+		return nil
+	}
+
+	violations := []string{}
+	previousRates := ratesFromRecord(previousRecord.Rates, previousRecord.RateFor(DefaultCurrency))
+	for _, rateChange := range compareRates(previousRates, newRates) {
+		if math.Abs(rateChange.ChangePercent) > service.maxDailyChangePercent {
+			violations = append(violations, fmt.Sprintf("%s: %s -> %s (%+.2f%%, máximo %.2f%%)",
+				rateChange.Currency, rateChange.PreviousRate, rateChange.NewRate, rateChange.ChangePercent, service.maxDailyChangePercent))
+		}
+	}
+	return violations
+}
+
+// quarantineRates retiene una publicación sospechosa en lugar de publicarla y alerta a los administradores.
+// Si ya hay una publicación pendiente idéntica (ej. un reintento que volvió a leer el mismo valor), no se duplica.
+func (service *BCVService) quarantineRates(runContext context.Context, publication RatePublication, previousRecord *models.BCVRate, violations []string) {
+	pendingRates, listErr := service.quarantineRepository.ListQuarantinedRates(runContext)
+	if listErr != nil {
+		slog.WarnContext(runContext, "Error al consultar la cuarentena de tasas", "error", listErr)
+	}
+	for _, pendingRate := range pendingRates {
+		if pendingRate.Status == QuarantinePending && sameDay(pendingRate.EffectiveDate, publication.EffectiveDate) &&
+			len(differingRates(pendingRate.Rates, publication.Rates)) == 0 {
+			slog.InfoContext(runContext, "La tasa sospechosa ya está en cuarentena. No se duplica.", "quarantine_id", pendingRate.ID)
+			return
+		}
+	}
+
+	quarantineID, idErr := randomHex(8)
+	if idErr != nil {
+		// Sin ID no se puede guardar para revisión; la tasa igual queda sin publicar.
+		slog.ErrorContext(runContext, "Error al generar el ID de la tasa en cuarentena", "error", idErr)
+		service.sendAlertAsync(runContext, fmt.Sprintf("Alerta: La nueva tasa del BCV (fecha valor %s, fuente '%s') supera la variación diaria máxima y no se publicó, pero no se pudo guardar en cuarentena: %v\n%s",
+			publication.EffectiveDate.Format("2006-01-02"), publication.Source, idErr, strings.Join(violations, "\n")))
+		return
+	}
+
+	quarantinedRate := models.QuarantinedRate{
+		ID:            quarantineID,
+		Source:        publication.Source,
+		Rates:         publication.Rates,
+		PreviousRates: ratesFromRecord(previousRecord.Rates, previousRecord.RateFor(DefaultCurrency)),
+		EffectiveDate: publication.EffectiveDate,
+		Reason:        strings.Join(violations, "; "),
+		Status:        QuarantinePending,
+		QuarantinedAt: time.Now(),
+	}
+	if saveErr := service.quarantineRepository.SaveQuarantinedRate(runContext, quarantinedRate); saveErr != nil {
+		slog.ErrorContext(runContext, "Error al guardar la tasa en cuarentena", "error", saveErr)
+	}
+
+	slog.WarnContext(runContext, "Tasa sospechosa en cuarentena", "quarantine_id", quarantinedRate.ID, "source", quarantinedRate.Source, "reason", quarantinedRate.Reason)
+	service.sendAlertAsync(runContext, fmt.Sprintf("Alerta: La nueva tasa del BCV (fecha valor %s, fuente '%s') supera la variación diaria máxima y quedó en cuarentena con el ID %s. Se mantiene la tasa anterior hasta que un administrador la apruebe o la rechace.\n%s",
+		publication.EffectiveDate.Format("2006-01-02"), publication.Source, quarantinedRate.ID, strings.Join(violations, "\n")))
+}
+
+// ListQuarantinedRates retorna las tasas en cuarentena, de la más reciente a la más antigua.
+// Si 'status' no está vacío, solo se retornan las que tengan ese estado.
+func (service *BCVService) ListQuarantinedRates(requestContext context.Context, status string) ([]models.QuarantinedRate, error) {
+	quarantinedRates, listErr := service.quarantineRepository.ListQuarantinedRates(requestContext)
+	if listErr != nil || status == "" {
+		return quarantinedRates, listErr
+	}
+	filteredRates := []models.QuarantinedRate{}
+	for _, quarantinedRate := range quarantinedRates {
+		if quarantinedRate.Status == status {
+			filteredRates = append(filteredRates, quarantinedRate)
+		}
+	}
+	return filteredRates, nil
+}
+
+// ApproveQuarantinedRate publica una tasa en cuarentena: la guarda como registro de tasas, la usa como tasa
+// actual si es al menos tan reciente como la vigente, y avisa del cambio a los suscriptores.
+// La tasa se marca como aprobada antes de guardarla, con una escritura condicionada a que siga pendiente,
+// para que dos aprobaciones simultáneas (incluso en instancias distintas) no la guarden dos veces.
+// Si el guardado falla, vuelve a quedar pendiente.
+func (service *BCVService) ApproveQuarantinedRate(requestContext context.Context, quarantineID string) (models.QuarantinedRate, error) {
+	service.updateMutex.Lock()
+	defer service.updateMutex.Unlock()
+
+	quarantinedRate, getErr := service.pendingQuarantinedRate(requestContext, quarantineID)
+	if getErr != nil {
+		return models.QuarantinedRate{}, getErr
+	}
+	approvedRate, markErr := service.markQuarantinedRate(requestContext, quarantinedRate, QuarantineApproved)
+	if markErr != nil {
+		return models.QuarantinedRate{}, markErr
+	}
+
+	saveErr := service.dbService.SaveBCVRate(requestContext, models.BCVRate{
+		Rates:         quarantinedRate.Rates,
+		EffectiveDate: quarantinedRate.EffectiveDate,
+		Timestamp:     time.Now().In(time.Local),
+		Source:        quarantinedRate.Source,
+	})
+	if saveErr != nil {
+		if _, revertErr := service.quarantineRepository.SaveQuarantinedRateIfStatus(requestContext, quarantinedRate, QuarantineApproved); revertErr != nil {
+			slog.ErrorContext(requestContext, "Error al devolver a pendiente la tasa en cuarentena no guardada", "quarantine_id", quarantineID, "error", revertErr)
+		}
+		return models.QuarantinedRate{}, saveErr
+	}
+
+	if !quarantinedRate.EffectiveDate.Before(service.CurrentRates().EffectiveDate) {
+		service.publishRates(newRateSnapshot(ratesFromRecord(quarantinedRate.Rates, quarantinedRate.Rates[DefaultCurrency]),
+			quarantinedRate.EffectiveDate, quarantinedRate.QuarantinedAt, RateSourceScrape, false, service.maxRateAge))
+	}
+
+	service.notifyRateChange(requestContext, quarantinedRate.PreviousRates, quarantinedRate.Rates, quarantinedRate.EffectiveDate)
+	slog.InfoContext(requestContext, "Tasa en cuarentena aprobada y publicada", "quarantine_id", quarantineID, "bcv", quarantinedRate.Rates[DefaultCurrency])
+	return approvedRate, nil
+}
+
+// RejectQuarantinedRate descarta una tasa en cuarentena; la tasa actual no cambia.
+func (service *BCVService) RejectQuarantinedRate(requestContext context.Context, quarantineID string) (models.QuarantinedRate, error) {
+	service.updateMutex.Lock()
+	defer service.updateMutex.Unlock()
+
+	quarantinedRate, getErr := service.pendingQuarantinedRate(requestContext, quarantineID)
+	if getErr != nil {
+		return models.QuarantinedRate{}, getErr
+	}
+	slog.InfoContext(requestContext, "Tasa en cuarentena rechazada", "quarantine_id", quarantineID)
+	return service.markQuarantinedRate(requestContext, quarantinedRate, QuarantineRejected)
+}
+
+// pendingQuarantinedRate obtiene una tasa en cuarentena que aún no fue revisada.
+func (service *BCVService) pendingQuarantinedRate(requestContext context.Context, quarantineID string) (models.QuarantinedRate, error) {
+	quarantinedRate, getErr := service.quarantineRepository.GetQuarantinedRate(requestContext, quarantineID)
+	if getErr != nil {
+		return models.QuarantinedRate{}, getErr
+	}
+	if quarantinedRate == nil {
+		return models.QuarantinedRate{}, ErrQuarantinedRateNotFound
+	}
+	if quarantinedRate.Status != QuarantinePending {
+		return models.QuarantinedRate{}, fmt.Errorf("%w: estado '%s'", ErrQuarantinedRateReviewed, quarantinedRate.Status)
+	}
+	return *quarantinedRate, nil
+}
+
+// markQuarantinedRate guarda el resultado de la revisión de una tasa en cuarentena, siempre que siga pendiente.
+// Retorna ErrQuarantinedRateReviewed si otra revisión se adelantó.
+func (service *BCVService) markQuarantinedRate(requestContext context.Context, quarantinedRate models.QuarantinedRate, status string) (models.QuarantinedRate, error) {
+	reviewedAt := time.Now()
+	quarantinedRate.Status = status
+	quarantinedRate.ReviewedAt = &reviewedAt
+	rateSaved, saveErr := service.quarantineRepository.SaveQuarantinedRateIfStatus(requestContext, quarantinedRate, QuarantinePending)
+	if saveErr != nil {
+		return models.QuarantinedRate{}, saveErr
+	}
+	if !rateSaved {
+		return models.QuarantinedRate{}, fmt.Errorf("%w: otra revisión se adelantó", ErrQuarantinedRateReviewed)
+	}
+	return quarantinedRate, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// faultyRateRepository es un repositorio en memoria cuyas lecturas de la última tasa o cuyos guardados fallan a pedido.
+type faultyRateRepository struct {
+	*MemoryRateRepository
+	latestErr error
+	saveErr   error
+}
+
+func (repository *faultyRateRepository) GetLatestBCVRate(operationContext context.Context) (*models.BCVRate, error) {
+	if repository.latestErr != nil {
+		return nil, repository.latestErr
+	}
+	return repository.MemoryRateRepository.GetLatestBCVRate(operationContext)
+}
+
+func (repository *faultyRateRepository) SaveBCVRate(operationContext context.Context, bcvRateDocument models.BCVRate) error {
+	if repository.saveErr != nil {
+		return repository.saveErr
+	}
+	return repository.MemoryRateRepository.SaveBCVRate(operationContext, bcvRateDocument)
+}
+
+// storedRecordCount retorna cuántos registros de tasas guarda el repositorio.
+func storedRecordCount(t *testing.T, rateRepository RateRepository) int64 {
+	t.Helper()
+	_, totalRecords, historyErr := rateRepository.GetBCVRateHistory(context.Background(), time.Time{}, time.Now().AddDate(1, 0, 0), 1, 1)
+	if historyErr != nil {
+		t.Fatal(historyErr)
+	}
+	return totalRecords
+}
+
+func TestRefreshRatesWithoutPreviousRate(t *testing.T) {
+	jsonServer := newStaticServer(t, http.StatusOK, "application/json", []byte(`{"rates":{"USD":"36.12345678"}}`))
+
+	testCases := []struct {
+		name                  string
+		maxDailyChangePercent float64
+		expectStored          bool
+	}{
+		// Con la verificación activa, una tasa que no se puede comparar no se publica ni se guarda.
+		{name: "verificación activa", maxDailyChangePercent: 10, expectStored: false},
+		{name: "verificación desactivada", maxDailyChangePercent: 0, expectStored: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rateRepository := &faultyRateRepository{MemoryRateRepository: NewMemoryRateRepository(), latestErr: errors.New("base de datos no disponible")}
+			appConfig := &config.Config{ScrapeMaxAttempts: 1, MaxDailyChangePercent: testCase.maxDailyChangePercent}
+			bcvService := NewBCVService(appConfig, rateRepository, NewNotificationDispatcherWith(), NewNotificationDispatcherWith(),
+				[]RateSource{NewJSONRateSource(jsonServer.URL, "rates", "")}, NewMemoryQuarantineRepository())
+
+			freshRateStored := bcvService.refreshRates(context.Background(), false)
+			if freshRateStored != testCase.expectStored {
+				t.Fatalf("refreshRates = %v; se esperaba %v", freshRateStored, testCase.expectStored)
+			}
+			publishedRate := bcvService.GetBCV()
+			if testCase.expectStored && publishedRate.String() != "36.12345678" {
+				t.Fatalf("tasa publicada = %s; se esperaba 36.12345678", publishedRate)
+			}
+			if !testCase.expectStored && !publishedRate.IsZero() {
+				t.Fatalf("tasa publicada = %s; se esperaba que se mantuviera la anterior (ninguna)", publishedRate)
+			}
+			if expectedCount := map[bool]int64{true: 1, false: 0}[testCase.expectStored]; storedRecordCount(t, rateRepository) != expectedCount {
+				t.Fatalf("registros guardados = %d; se esperaban %d", storedRecordCount(t, rateRepository), expectedCount)
+			}
+		})
+	}
+}
+
+// newQuarantineFixture crea un BCVService con una tasa pendiente en cuarentena y retorna su ID.
+func newQuarantineFixture(t *testing.T, rateRepository RateRepository) (*BCVService, QuarantineRepository, string) {
+	t.Helper()
+	quarantineRepository := NewMemoryQuarantineRepository()
+	bcvService := NewBCVService(&config.Config{}, rateRepository, NewNotificationDispatcherWith(), NewNotificationDispatcherWith(), nil, quarantineRepository)
+	pendingRate := models.QuarantinedRate{
+		ID:            "q1",
+		Source:        "bcv_page",
+		Rates:         map[string]decimal.Decimal{"USD": decimal.RequireFromString("3.61")},
+		PreviousRates: map[string]decimal.Decimal{"USD": decimal.RequireFromString("36.1")},
+		EffectiveDate: time.Date(2024, 3, 18, 0, 0, 0, 0, time.Local),
+		Status:        QuarantinePending,
+		QuarantinedAt: time.Now(),
+	}
+	if saveErr := quarantineRepository.SaveQuarantinedRate(context.Background(), pendingRate); saveErr != nil {
+		t.Fatal(saveErr)
+	}
+	return bcvService, quarantineRepository, pendingRate.ID
+}
+
+func TestApproveQuarantinedRateOnce(t *testing.T) {
+	rateRepository := NewMemoryRateRepository()
+	bcvService, _, quarantineID := newQuarantineFixture(t, rateRepository)
+
+	approvedRate, approveErr := bcvService.ApproveQuarantinedRate(context.Background(), quarantineID)
+	if approveErr != nil || approvedRate.Status != QuarantineApproved || approvedRate.ReviewedAt == nil {
+		t.Fatalf("ApproveQuarantinedRate = %+v, %v; se esperaba la tasa aprobada", approvedRate, approveErr)
+	}
+	if _, approveErr = bcvService.ApproveQuarantinedRate(context.Background(), quarantineID); !errors.Is(approveErr, ErrQuarantinedRateReviewed) {
+		t.Fatalf("la segunda aprobación retornó %v; se esperaba ErrQuarantinedRateReviewed", approveErr)
+	}
+	if recordCount := storedRecordCount(t, rateRepository); recordCount != 1 {
+		t.Fatalf("registros guardados = %d; se esperaba 1", recordCount)
+	}
+}
+
+func TestApproveQuarantinedRateSaveFailure(t *testing.T) {
+	rateRepository := &faultyRateRepository{MemoryRateRepository: NewMemoryRateRepository(), saveErr: errors.New("base de datos no disponible")}
+	bcvService, quarantineRepository, quarantineID := newQuarantineFixture(t, rateRepository)
+
+	if _, approveErr := bcvService.ApproveQuarantinedRate(context.Background(), quarantineID); approveErr == nil {
+		t.Fatal("ApproveQuarantinedRate no retornó el error del guardado")
+	}
+	// Si la tasa no se guardó, sigue pendiente para que se pueda volver a aprobar.
+	storedRate, getErr := quarantineRepository.GetQuarantinedRate(context.Background(), quarantineID)
+	if getErr != nil || storedRate.Status != QuarantinePending || storedRate.ReviewedAt != nil {
+		t.Fatalf("tasa en cuarentena = %+v, %v; se esperaba que siguiera pendiente", storedRate, getErr)
+	}
+
+	rateRepository.saveErr = nil
+	if _, approveErr := bcvService.ApproveQuarantinedRate(context.Background(), quarantineID); approveErr != nil {
+		t.Fatalf("el segundo intento de aprobación retornó %v", approveErr)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateRepository define las operaciones de almacenamiento de tasas BCV que necesita BCVService.
+// Existen implementaciones con MongoDB, en memoria y en un archivo local bbolt.
+type RateRepository interface {
+	SaveBCVRate(operationContext context.Context, bcvRateDocument models.BCVRate) error
+	GetLatestBCVRate(operationContext context.Context) (*models.BCVRate, error)
+	GetBCVRateForToday(operationContext context.Context) (*models.BCVRate, error)
+	GetBCVRateInEffectOn(operationContext context.Context, effectiveTimestamp time.Time) (*models.BCVRate, error)
+	GetBCVRateOn(operationContext context.Context, effectiveDay time.Time) (*models.BCVRate, error)
+	GetBCVRateHistory(operationContext context.Context, fromTimestamp, toTimestamp time.Time, pageNumber, pageSize int) ([]models.BCVRate, int64, error)
+	DeleteBCVRatesOn(operationContext context.Context, effectiveDay time.Time) (int64, error)
+	ReplaceBCVRatesOn(operationContext context.Context, effectiveDay time.Time, bcvRateDocument models.BCVRate) (int64, error)
+	Ping(operationContext context.Context) error
+	Disconnect(operationContext context.Context)
+}
+
+// Verificaciones en tiempo de compilación de que cada backend implementa RateRepository.
+var (
+	_ RateRepository = (*MongoDBService)(nil)
+	_ RateRepository = (*MemoryRateRepository)(nil)
+	_ RateRepository = (*BoltRateRepository)(nil)
+)
+
+// NewRateRepository crea el repositorio de tasas indicado por 'StorageBackend' en la configuración.
+func NewRateRepository(appConfig *config.Config) (RateRepository, error) {
+	switch appConfig.StorageBackend {
+	case "", "mongo":
+		return NewMongoDBService(appConfig)
+	case "memory":
+		return NewMemoryRateRepository(), nil
+	case "bolt":
+		return NewBoltRateRepository(appConfig.BoltDBPath)
+	default:
+		return nil, fmt.Errorf("backend de almacenamiento desconocido: '%s'", appConfig.StorageBackend)
+	}
+}
+
+// Las siguientes funciones implementan las consultas sobre un slice de registros,
+// y son compartidas por los repositorios que no cuentan con un motor de consultas (memoria y bbolt).
+
+// latestRateRecord retorna el registro con el timestamp más reciente, o nil si no hay registros.
+func latestRateRecord(rateRecords []models.BCVRate) *models.BCVRate {
+	var latestRecord *models.BCVRate
+	for recordIndex := range rateRecords {
+		if latestRecord == nil || rateRecords[recordIndex].Timestamp.After(latestRecord.Timestamp) {
+			latestRecord = &rateRecords[recordIndex]
+		}
+	}
+	return latestRecord
+}
+
+// todayRateRecord retorna el registro más reciente guardado durante el día actual (hora local).
+func todayRateRecord(rateRecords []models.BCVRate) *models.BCVRate {
+	currentTime := time.Now().In(time.Local)
+	startOfCurrentDay := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, currentTime.Location())
+	endOfCurrentDay := startOfCurrentDay.Add(24 * time.Hour)
+
+	todayRecords := []models.BCVRate{}
+	for _, rateRecord := range rateRecords {
+		if !rateRecord.Timestamp.Before(startOfCurrentDay) && rateRecord.Timestamp.Before(endOfCurrentDay) {
+			todayRecords = append(todayRecords, rateRecord)
+		}
+	}
+	return latestRateRecord(todayRecords)
+}
+
+// rateRecordInEffectOn retorna el registro con la "Fecha Valor" más reciente que no sea posterior
+// al instante indicado. Los registros sin fecha valor usan su timestamp.
+func rateRecordInEffectOn(rateRecords []models.BCVRate, effectiveTimestamp time.Time) *models.BCVRate {
+	var effectiveRecord *models.BCVRate
+	for recordIndex := range rateRecords {
+		candidateRecord := &rateRecords[recordIndex]
+		candidateDate := recordEffectiveDate(*candidateRecord)
+		if candidateDate.After(effectiveTimestamp) {
+			continue
+		}
+		if effectiveRecord == nil {
+			effectiveRecord = candidateRecord
+			continue
+		}
+		currentDate := recordEffectiveDate(*effectiveRecord)
+		if candidateDate.After(currentDate) || (candidateDate.Equal(currentDate) && candidateRecord.Timestamp.After(effectiveRecord.Timestamp)) {
+			effectiveRecord = candidateRecord
+		}
+	}
+	return effectiveRecord
+}
+
+// latestRateRecordOn retorna el registro más reciente (por timestamp) cuya fecha valor cae en el día que comienza
+// en 'startOfDay', o nil si no hay ninguno.
+func latestRateRecordOn(rateRecords []models.BCVRate, startOfDay time.Time) *models.BCVRate {
+	dayRecords := []models.BCVRate{}
+	for _, rateRecord := range rateRecords {
+		if isRecordOnDay(rateRecord, startOfDay) {
+			dayRecords = append(dayRecords, rateRecord)
+		}
+	}
+	return latestRateRecord(dayRecords)
+}
+
+// isRecordOnDay indica si la fecha valor del registro cae en el día que comienza en 'startOfDay'.
+func isRecordOnDay(rateRecord models.BCVRate, startOfDay time.Time) bool {
+	recordDate := recordEffectiveDate(rateRecord)
+	return !recordDate.Before(startOfDay) && recordDate.Before(startOfDay.AddDate(0, 0, 1))
+}
+
+// recordEffectiveDate retorna la fecha valor del registro, o su timestamp si no la tiene.
+func recordEffectiveDate(rateRecord models.BCVRate) time.Time {
+	if rateRecord.EffectiveDate.IsZero() {
+		return rateRecord.Timestamp
+	}
+	return rateRecord.EffectiveDate
+}
+
+// rateHistoryPage filtra los registros cuyo timestamp está en el rango (inclusive), los ordena
+// de forma ascendente y retorna la página solicitada junto con el total de registros en el rango.
+func rateHistoryPage(rateRecords []models.BCVRate, fromTimestamp, toTimestamp time.Time, pageNumber, pageSize int) ([]models.BCVRate, int64) {
+	rangeRecords := []models.BCVRate{}
+	for _, rateRecord := range rateRecords {
+		if !rateRecord.Timestamp.Before(fromTimestamp) && !rateRecord.Timestamp.After(toTimestamp) {
+			rangeRecords = append(rangeRecords, rateRecord)
+		}
+	}
+	sort.SliceStable(rangeRecords, func(i, j int) bool {
+		return rangeRecords[i].Timestamp.Before(rangeRecords[j].Timestamp)
+	})
+
+	// Se compara antes de multiplicar para que una página enorme no desborde el cálculo del inicio.
+	if pageNumber-1 > len(rangeRecords)/pageSize {
+		return []models.BCVRate{}, int64(len(rangeRecords))
+	}
+	pageStart := (pageNumber - 1) * pageSize
+	if pageStart >= len(rangeRecords) {
+		return []models.BCVRate{}, int64(len(rangeRecords))
+	}
+	pageEnd := pageStart + pageSize
+	if pageEnd > len(rangeRecords) {
+		pageEnd = len(rangeRecords)
+	}
+	return rangeRecords[pageStart:pageEnd], int64(len(rangeRecords))
+}
+
+// copyRateRecord retorna una copia del registro para que los llamadores no modifiquen el almacenamiento.
+func copyRateRecord(rateRecord *models.BCVRate) *models.BCVRate {
+	if rateRecord == nil {
+		return nil
+	}
+	copiedRecord := *rateRecord
+	copiedRecord.Rates = make(map[string]decimal.Decimal, len(rateRecord.Rates))
+	for currency, currencyRate := range rateRecord.Rates {
+		copiedRecord.Rates[currency] = currencyRate
+	}
+	return &copiedRecord
+}
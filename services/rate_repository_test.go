@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateRepositoryBackends retorna un repositorio de tasas vacío por cada backend disponible: memoria, bbolt y,
+// si TEST_MONGODB_URI tiene valor, MongoDB (en una base de datos temporal que se elimina al terminar).
+func rateRepositoryBackends(t *testing.T) map[string]RateRepository {
+	t.Helper()
+	boltRepository, boltErr := NewBoltRateRepository(filepath.Join(t.TempDir(), "tasas.db"))
+	if boltErr != nil {
+		t.Fatal(boltErr)
+	}
+	t.Cleanup(func() { boltRepository.Disconnect(context.Background()) })
+	rateRepositories := map[string]RateRepository{
+		"memory": NewMemoryRateRepository(),
+		"bolt":   boltRepository,
+	}
+
+	if mongoURI := os.Getenv("TEST_MONGODB_URI"); mongoURI != "" {
+		testDatabaseName := fmt.Sprintf("precio_bcv_test_%d", time.Now().UnixNano())
+		mongoRepository, mongoErr := NewMongoDBService(&config.Config{
+			MongoDBURI:               mongoURI,
+			DatabaseName:             testDatabaseName,
+			CollectionName:           "bcv_rates",
+			PlansCollectionName:      "plans",
+			QuarantineCollectionName: "quarantine",
+			APIKeysCollectionName:    "api_keys",
+		})
+		if mongoErr != nil {
+			t.Fatal(mongoErr)
+		}
+		t.Cleanup(func() {
+			mongoRepository.client.Database(testDatabaseName).Drop(context.Background())
+			mongoRepository.Disconnect(context.Background())
+		})
+		rateRepositories["mongo"] = mongoRepository
+	}
+	return rateRepositories
+}
+
+func TestRateHistoryPage(t *testing.T) {
+	baseTime := time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)
+	rateRecords := []models.BCVRate{}
+	for dayOffset := 4; dayOffset >= 0; dayOffset-- { // Desordenados a propósito.
+		rateRecords = append(rateRecords, models.BCVRate{Timestamp: baseTime.AddDate(0, 0, dayOffset)})
+	}
+	fromTimestamp, toTimestamp := baseTime, baseTime.AddDate(0, 0, 3) // Excluye el último día.
+
+	testCases := []struct {
+		name         string
+		pageNumber   int
+		pageSize     int
+		expectedDays []int // Desplazamiento en días de cada registro esperado.
+	}{
+		{name: "primera página", pageNumber: 1, pageSize: 3, expectedDays: []int{0, 1, 2}},
+		{name: "última página incompleta", pageNumber: 2, pageSize: 3, expectedDays: []int{3}},
+		{name: "página posterior a la última", pageNumber: 3, pageSize: 3, expectedDays: []int{}},
+		{name: "página que desbordaría el salto", pageNumber: math.MaxInt, pageSize: 366, expectedDays: []int{}},
+		{name: "página máxima con un registro por página", pageNumber: math.MaxInt, pageSize: 1, expectedDays: []int{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pageRecords, totalRecords := rateHistoryPage(rateRecords, fromTimestamp, toTimestamp, testCase.pageNumber, testCase.pageSize)
+			if totalRecords != 4 {
+				t.Fatalf("total = %d; se esperaba 4", totalRecords)
+			}
+			if len(pageRecords) != len(testCase.expectedDays) {
+				t.Fatalf("la página tiene %d registros; se esperaban %d", len(pageRecords), len(testCase.expectedDays))
+			}
+			for recordIndex, expectedDay := range testCase.expectedDays {
+				if expectedTimestamp := baseTime.AddDate(0, 0, expectedDay); !pageRecords[recordIndex].Timestamp.Equal(expectedTimestamp) {
+					t.Fatalf("registro %d = %s; se esperaba %s", recordIndex, pageRecords[recordIndex].Timestamp, expectedTimestamp)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBCVRateInEffectOnMixedRecords(t *testing.T) {
+	dayAt := func(day, hour int) time.Time { return time.Date(2024, 3, day, hour, 0, 0, 0, time.Local) }
+	// Mezcla de registros con fecha valor y registros anteriores a su captura, que solo tienen timestamp.
+	fixtureRecords := []models.BCVRate{
+		{Value: decimal.RequireFromString("36.01"), EffectiveDate: dayAt(15, 0), Timestamp: dayAt(14, 16)},
+		{Value: decimal.RequireFromString("36.02"), Timestamp: dayAt(18, 9)}, // Sin fecha valor.
+		{Value: decimal.RequireFromString("36.03"), EffectiveDate: dayAt(18, 0), Timestamp: dayAt(15, 16)},
+		{Value: decimal.RequireFromString("36.04"), EffectiveDate: dayAt(20, 0), Timestamp: dayAt(19, 16)},
+	}
+	testCases := []struct {
+		name          string
+		effectiveAt   time.Time
+		expectedValue string // Vacío si no se espera ningún registro vigente.
+	}{
+		{name: "antes del primer registro", effectiveAt: dayAt(10, 12), expectedValue: ""},
+		{name: "fin de semana con fecha valor", effectiveAt: dayAt(16, 12), expectedValue: "36.01"},
+		{name: "registro sin fecha valor más reciente que uno con fecha valor", effectiveAt: dayAt(18, 23), expectedValue: "36.02"},
+		{name: "fecha valor futura ya vigente", effectiveAt: dayAt(20, 12), expectedValue: "36.04"},
+	}
+
+	for backendName, rateRepository := range rateRepositoryBackends(t) {
+		t.Run(backendName, func(t *testing.T) {
+			for _, fixtureRecord := range fixtureRecords {
+				if saveErr := rateRepository.SaveBCVRate(context.Background(), fixtureRecord); saveErr != nil {
+					t.Fatal(saveErr)
+				}
+			}
+			for _, testCase := range testCases {
+				t.Run(testCase.name, func(t *testing.T) {
+					effectiveRecord, lookupErr := rateRepository.GetBCVRateInEffectOn(context.Background(), testCase.effectiveAt)
+					if lookupErr != nil {
+						t.Fatalf("GetBCVRateInEffectOn retornó un error: %v", lookupErr)
+					}
+					if testCase.expectedValue == "" {
+						if effectiveRecord != nil {
+							t.Fatalf("registro vigente = %s; no se esperaba ninguno", effectiveRecord.Value)
+						}
+						return
+					}
+					if effectiveRecord == nil || effectiveRecord.Value.String() != testCase.expectedValue {
+						t.Fatalf("registro vigente = %+v; se esperaba el de %s", effectiveRecord, testCase.expectedValue)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"precio-bcv-go/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// RateSnapshot es el estado de las tasas actuales del BCVService. Es inmutable: cada actualización publica
+// un snapshot nuevo y reemplaza al anterior, por lo que los lectores pueden usarlo sin bloqueo y todos sus
+// campos corresponden a la misma actualización. Nunca deben modificarse sus campos ni el mapa Rates.
+type RateSnapshot struct {
+	Value         decimal.Decimal            // Tasa del dólar; 0 si no hay tasa disponible.
+	Rates         map[string]decimal.Decimal // Bolívares por unidad de cada moneda publicada.
+	EffectiveDate time.Time                  // "Fecha Valor" de las tasas; cero si no hay tasa.
+	FetchedAt     time.Time                  // Cuándo se obtuvieron las tasas de su origen.
+	Source        string                     // RateSourceScrape, RateSourceDBToday, RateSourceDBFallback o RateSourceManual.
+	Fallback      bool                       // true si la actualización no logró las tasas del día y se usa un valor anterior.
+
+	maxRateAge time.Duration // Antigüedad de la fecha valor a partir de la cual las tasas están desactualizadas.
+}
+
+// newRateSnapshot crea un snapshot con las tasas indicadas; 'rates' pasa a pertenecer al snapshot.
+func newRateSnapshot(rates map[string]decimal.Decimal, effectiveDate, fetchedAt time.Time, source string, fallback bool, maxRateAge time.Duration) *RateSnapshot {
+	if rates == nil {
+		rates = map[string]decimal.Decimal{}
+	}
+	return &RateSnapshot{
+		Value:         rates[DefaultCurrency],
+		Rates:         rates,
+		EffectiveDate: effectiveDate,
+		FetchedAt:     fetchedAt,
+		Source:        source,
+		Fallback:      fallback,
+		maxRateAge:    maxRateAge,
+	}
+}
+
+// Rate retorna la tasa de la moneda indicada (código ISO, ej. "EUR"), o 0 si no está disponible.
+func (snapshot *RateSnapshot) Rate(currency string) decimal.Decimal {
+	return snapshot.Rates[currency]
+}
+
+// bolivarRate retorna cuántos bolívares vale una unidad de la moneda; el bolívar vale 1.
+func (snapshot *RateSnapshot) bolivarRate(currency string) decimal.Decimal {
+	if currency == BolivarCurrency {
+		return decimal.NewFromInt(1)
+	}
+	return snapshot.Rates[currency]
+}
+
+// ConversionRate retorna cuántas unidades de 'toCurrency' equivale una unidad de 'fromCurrency'.
+// Las conversiones entre divisas (ej. EUR a USD) se calculan a través del bolívar, por lo que el resultado
+// puede no ser exacto (ej. 1/3); se calcula con 16 decimales y quien lo use debe redondearlo.
+// Retorna false si alguna de las tasas no está disponible.
+func (snapshot *RateSnapshot) ConversionRate(fromCurrency, toCurrency string) (decimal.Decimal, bool) {
+	fromBolivarRate := snapshot.bolivarRate(fromCurrency)
+	toBolivarRate := snapshot.bolivarRate(toCurrency)
+	if !fromBolivarRate.IsPositive() || !toBolivarRate.IsPositive() {
+		return decimal.Zero, false
+	}
+	return fromBolivarRate.Div(toBolivarRate), true
+}
+
+// Convert convierte 'amount' de 'fromCurrency' a 'toCurrency', sin redondear. Multiplica antes de dividir,
+// de modo que la única pérdida de precisión es la de la división final. Retorna false si falta alguna tasa.
+func (snapshot *RateSnapshot) Convert(amount decimal.Decimal, fromCurrency, toCurrency string) (decimal.Decimal, bool) {
+	fromBolivarRate := snapshot.bolivarRate(fromCurrency)
+	toBolivarRate := snapshot.bolivarRate(toCurrency)
+	if !fromBolivarRate.IsPositive() || !toBolivarRate.IsPositive() {
+		return decimal.Zero, false
+	}
+	return amount.Mul(fromBolivarRate).Div(toBolivarRate), true
+}
+
+// Stale indica si las tasas están desactualizadas (ver StaleReason).
+func (snapshot *RateSnapshot) Stale() bool {
+	return snapshot.StaleReason() != ""
+}
+
+// StaleReason retorna por qué las tasas están desactualizadas, o "" si no lo están. Es la regla que usan tanto
+// el campo "stale" de las respuestas como la sonda de readiness, por lo que el texto está en inglés, como el
+// resto de la API. Están desactualizadas si la actualización tuvo que usar un valor anterior, o si su antigüedad
+// supera la máxima configurada. La antigüedad se mide desde la fecha valor o, si no se conoce, desde que se
+// obtuvieron; sin ninguna de las dos no hay forma de saber qué tan viejas son.
+func (snapshot *RateSnapshot) StaleReason() string {
+	switch {
+	case snapshot.Fallback:
+		return "rate is the last known value; today's rate could not be obtained"
+	case !snapshot.EffectiveDate.IsZero():
+		if snapshot.maxRateAge > 0 && time.Since(snapshot.EffectiveDate) > snapshot.maxRateAge {
+			return fmt.Sprintf("rate is older than %s", snapshot.maxRateAge)
+		}
+	case !snapshot.FetchedAt.IsZero():
+		if snapshot.maxRateAge > 0 && time.Since(snapshot.FetchedAt) > snapshot.maxRateAge {
+			return fmt.Sprintf("rate date unknown and rate was fetched more than %s ago", snapshot.maxRateAge)
+		}
+	default:
+		return "rate date unknown"
+	}
+	return ""
+}
+
+// Metadata describe las tasas del snapshot para las respuestas de la API.
+func (snapshot *RateSnapshot) Metadata() models.RateMetadata {
+	rateMetadata := models.RateMetadata{
+		FetchedAt: snapshot.FetchedAt,
+		Source:    snapshot.Source,
+		Stale:     snapshot.Stale(),
+	}
+	if !snapshot.EffectiveDate.IsZero() {
+		rateMetadata.RateDate = snapshot.EffectiveDate.In(time.Local).Format("2006-01-02")
+	}
+	return rateMetadata
+}
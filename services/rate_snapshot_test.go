@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRateSnapshotStaleReason(t *testing.T) {
+	maxRateAge := 72 * time.Hour
+	recentTime := time.Now().Add(-time.Hour)
+	oldTime := time.Now().Add(-100 * time.Hour)
+	testCases := []struct {
+		name          string
+		effectiveDate time.Time
+		fetchedAt     time.Time
+		fallback      bool
+		maxRateAge    time.Duration
+		expectStale   bool
+	}{
+		{name: "fecha valor reciente", effectiveDate: recentTime, fetchedAt: oldTime, maxRateAge: maxRateAge, expectStale: false},
+		{name: "fecha valor vieja", effectiveDate: oldTime, fetchedAt: recentTime, maxRateAge: maxRateAge, expectStale: true},
+		{name: "valor anterior por falla de la actualización", effectiveDate: recentTime, fetchedAt: recentTime, fallback: true, maxRateAge: maxRateAge, expectStale: true},
+		{name: "sin fecha valor y obtenida hace poco", fetchedAt: recentTime, maxRateAge: maxRateAge, expectStale: false},
+		{name: "sin fecha valor y obtenida hace mucho", fetchedAt: oldTime, maxRateAge: maxRateAge, expectStale: true},
+		{name: "sin fecha valor ni fecha de obtención", maxRateAge: maxRateAge, expectStale: true},
+		{name: "antigüedad máxima desactivada", effectiveDate: oldTime, fetchedAt: oldTime, maxRateAge: 0, expectStale: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rateSnapshot := newRateSnapshot(map[string]decimal.Decimal{DefaultCurrency: decimal.NewFromInt(36)}, testCase.effectiveDate, testCase.fetchedAt, RateSourceScrape, testCase.fallback, testCase.maxRateAge)
+			staleReason := rateSnapshot.StaleReason()
+			if (staleReason != "") != testCase.expectStale || rateSnapshot.Stale() != testCase.expectStale {
+				t.Fatalf("StaleReason = %q, Stale = %v; se esperaba desactualizada = %v", staleReason, rateSnapshot.Stale(), testCase.expectStale)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/metrics"
+	"precio-bcv-go/tracing"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RatePublication es un conjunto de tasas publicado por una fuente, con su "Fecha Valor".
+// EffectiveDate queda en cero si la fuente no indicó la fecha.
+type RatePublication struct {
+	Source        string
+	Rates         map[string]decimal.Decimal
+	EffectiveDate time.Time
+}
+
+// RateSource es una fuente de las tasas oficiales (página del BCV, boletines XLS, APIs JSON...).
+type RateSource interface {
+	Name() string
+	FetchRates(fetchContext context.Context) (RatePublication, error)
+}
+
+// NewRateSources crea las fuentes listadas en RATE_SOURCES, en el mismo orden de prioridad.
+// Retorna un error si una fuente es desconocida o le falta su URL.
+func NewRateSources(appConfig *config.Config) ([]RateSource, error) {
+	rateSources := make([]RateSource, 0, len(appConfig.RateSources))
+	for _, sourceName := range appConfig.RateSources {
+		switch sourceName {
+		case "bcv_page":
+			rateSources = append(rateSources, NewBCVPageSource(appConfig.BCVPageURL))
+		case "bcv_xls":
+			if appConfig.BCVBulletinURL == "" {
+				return nil, fmt.Errorf("la fuente 'bcv_xls' requiere la variable BCV_XLS_URL")
+			}
+			rateSources = append(rateSources, NewBCVBulletinSource(appConfig.BCVBulletinURL))
+		case "json":
+			if appConfig.RateJSONURL == "" {
+				return nil, fmt.Errorf("la fuente 'json' requiere la variable RATE_JSON_URL")
+			}
+			rateSources = append(rateSources, NewJSONRateSource(appConfig.RateJSONURL, appConfig.RateJSONRatesField, appConfig.RateJSONDateField))
+		default:
+			return nil, fmt.Errorf("fuente de tasas desconocida en RATE_SOURCES: '%s' (use bcv_page, bcv_xls o json)", sourceName)
+		}
+	}
+	slog.Info("Fuentes de tasas en orden de prioridad", "sources", appConfig.RateSources)
+	return rateSources, nil
+}
+
+// fetchFromSources consulta las fuentes en orden de prioridad y retorna la primera publicación
+// que incluya la tasa del dólar, junto con la posición de la fuente que la publicó.
+// Si ninguna fuente responde, retorna una publicación vacía y los errores combinados.
+func fetchFromSources(fetchContext context.Context, rateSources []RateSource) (RatePublication, int, error) {
+	sourceErrors := make([]string, 0, len(rateSources))
+	for sourceIndex, rateSource := range rateSources {
+		metrics.RateFetchAttempts.WithLabelValues(rateSource.Name()).Inc()
+		fetchStart := time.Now()
+		publication, fetchErr := fetchRatesTraced(fetchContext, rateSource)
+		metrics.RateFetchDuration.WithLabelValues(rateSource.Name()).Observe(time.Since(fetchStart).Seconds())
+		if fetchErr == nil && !publication.Rates[DefaultCurrency].IsPositive() {
+			fetchErr = fmt.Errorf("no publicó la tasa de %s", DefaultCurrency)
+		}
+		metrics.RateFetchResults.WithLabelValues(rateSource.Name(), metrics.Outcome(fetchErr)).Inc()
+		if fetchErr != nil {
+			slog.WarnContext(fetchContext, "La fuente de tasas falló", "source", rateSource.Name(), "error", fetchErr)
+			sourceErrors = append(sourceErrors, fmt.Sprintf("%s: %v", rateSource.Name(), fetchErr))
+			continue
+		}
+		publication.Source = rateSource.Name()
+		slog.InfoContext(fetchContext, "Tasas obtenidas", "source", publication.Source, "currencies", len(publication.Rates), "usd", publication.Rates[DefaultCurrency])
+		return publication, sourceIndex, nil
+	}
+	return RatePublication{Rates: map[string]decimal.Decimal{}}, -1, fmt.Errorf("ninguna fuente de tasas respondió: %s", strings.Join(sourceErrors, "; "))
+}
+
+// fetchRatesTraced consulta 'rateSource' dentro de un span propio, marcado con error si la consulta falla.
+func fetchRatesTraced(fetchContext context.Context, rateSource RateSource) (RatePublication, error) {
+	fetchContext, fetchSpan := tracing.Tracer().Start(fetchContext, "rate_source.fetch",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("rate.source", rateSource.Name())))
+	defer fetchSpan.End()
+
+	publication, fetchErr := rateSource.FetchRates(fetchContext)
+	tracing.RecordError(fetchSpan, fetchErr)
+	return publication, fetchErr
+}
+
+// compareRatePublications lista las monedas en las que 'otherPublication' difiere de 'primaryPublication'
+// en más de 'tolerancePercent'. Si ambas fuentes indican fechas valor distintas, no se comparan,
+// ya que una fuente puede simplemente no haber publicado aún la tasa nueva.
+func compareRatePublications(compareContext context.Context, primaryPublication, otherPublication RatePublication, tolerancePercent float64) []string {
+	if !primaryPublication.EffectiveDate.IsZero() && !otherPublication.EffectiveDate.IsZero() &&
+		!sameDay(primaryPublication.EffectiveDate, otherPublication.EffectiveDate) {
+		slog.InfoContext(compareContext, "Las fuentes publican fechas valor distintas. No se comparan.",
+			"primary_source", primaryPublication.Source, "primary_effective_date", primaryPublication.EffectiveDate.Format("2006-01-02"),
+			"other_source", otherPublication.Source, "other_effective_date", otherPublication.EffectiveDate.Format("2006-01-02"))
+		return nil
+	}
+
+	currencies := make([]string, 0, len(primaryPublication.Rates))
+	for currency := range primaryPublication.Rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	disagreements := []string{}
+	for _, currency := range currencies {
+		primaryRate := primaryPublication.Rates[currency]
+		otherRate, published := otherPublication.Rates[currency]
+		if !published || !primaryRate.IsPositive() || !otherRate.IsPositive() {
+			continue
+		}
+		differencePercent := math.Abs(percentChange(primaryRate, otherRate))
+		if differencePercent > tolerancePercent {
+			disagreements = append(disagreements, fmt.Sprintf("%s: %s=%s, %s=%s (%.2f%%)",
+				currency, primaryPublication.Source, primaryRate, otherPublication.Source, otherRate, differencePercent))
+		}
+	}
+	return disagreements
+}
+
+// sameDay indica si dos instantes caen en el mismo día calendario de la zona horaria del servidor.
+func sameDay(firstTimestamp, secondTimestamp time.Time) bool {
+	firstYear, firstMonth, firstDay := firstTimestamp.In(time.Local).Date()
+	secondYear, secondMonth, secondDay := secondTimestamp.In(time.Local).Date()
+	return firstYear == secondYear && firstMonth == secondMonth && firstDay == secondDay
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// countingServer envuelve un servidor de prueba y cuenta las peticiones que recibe.
+type countingServer struct {
+	*httptest.Server
+	requestCount atomic.Int32
+}
+
+// newCountingServer crea un servidor httptest que responde 'statusCode' con 'responseBody' y cuenta las peticiones.
+func newCountingServer(t *testing.T, statusCode int, responseBody []byte) *countingServer {
+	t.Helper()
+	server := &countingServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(httpResponseWriter http.ResponseWriter, _ *http.Request) {
+		server.requestCount.Add(1)
+		httpResponseWriter.WriteHeader(statusCode)
+		httpResponseWriter.Write(responseBody)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchFromSourcesFallbackOrder(t *testing.T) {
+	standardPage, readErr := os.ReadFile(filepath.Join("testdata", "bcv_page", "standard.html"))
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	// La página del BCV falla, la API JSON responde sin la tasa del dólar y el boletín no debe consultarse
+	// porque la segunda API JSON ya publica las tasas.
+	failingPage := newCountingServer(t, http.StatusInternalServerError, []byte("error"))
+	jsonWithoutUSD := newCountingServer(t, http.StatusOK, []byte(`{"rates":{"EUR":39.2}}`))
+	workingJSON := newCountingServer(t, http.StatusOK, []byte(`{"rates":{"USD":"36.12345678","EUR":"39.26457118"}}`))
+	unusedBulletin := newCountingServer(t, http.StatusOK, []byte("sin usar"))
+	rateSources := []RateSource{
+		NewBCVPageSource(failingPage.URL),
+		NewJSONRateSource(jsonWithoutUSD.URL, "rates", ""),
+		NewJSONRateSource(workingJSON.URL, "rates", ""),
+		NewBCVBulletinSource(unusedBulletin.URL),
+	}
+
+	publication, sourceIndex, fetchErr := fetchFromSources(context.Background(), rateSources)
+	if fetchErr != nil {
+		t.Fatalf("fetchFromSources retornó un error: %v", fetchErr)
+	}
+	if sourceIndex != 2 || publication.Source != "json" || publication.Rates[DefaultCurrency].String() != "36.12345678" {
+		t.Fatalf("fuente %d (%s) con USD=%s; se esperaba la fuente 2 (json) con USD=36.12345678", sourceIndex, publication.Source, publication.Rates[DefaultCurrency])
+	}
+	for serverName, server := range map[string]*countingServer{"página": failingPage, "json sin USD": jsonWithoutUSD, "json": workingJSON} {
+		if server.requestCount.Load() != 1 {
+			t.Fatalf("la fuente %s recibió %d peticiones; se esperaba 1", serverName, server.requestCount.Load())
+		}
+	}
+	if unusedBulletin.requestCount.Load() != 0 {
+		t.Fatal("se consultó el boletín aunque una fuente de mayor prioridad ya había respondido")
+	}
+
+	// Si la página vuelve a responder, tiene prioridad sobre las demás.
+	workingPage := newCountingServer(t, http.StatusOK, standardPage)
+	rateSources[0] = NewBCVPageSource(workingPage.URL)
+	publication, sourceIndex, fetchErr = fetchFromSources(context.Background(), rateSources)
+	if fetchErr != nil || sourceIndex != 0 || publication.Source != "bcv_page" {
+		t.Fatalf("fuente %d (%s), error %v; se esperaba la fuente 0 (bcv_page)", sourceIndex, publication.Source, fetchErr)
+	}
+}
+
+func TestFetchFromSourcesAllFailing(t *testing.T) {
+	failingPage := newCountingServer(t, http.StatusBadGateway, []byte("error"))
+	malformedJSON := newCountingServer(t, http.StatusOK, []byte("{"))
+	rateSources := []RateSource{NewBCVPageSource(failingPage.URL), NewJSONRateSource(malformedJSON.URL, "rates", "")}
+
+	publication, sourceIndex, fetchErr := fetchFromSources(context.Background(), rateSources)
+	if fetchErr == nil || sourceIndex != -1 {
+		t.Fatalf("fuente %d, error %v; se esperaba -1 y un error", sourceIndex, fetchErr)
+	}
+	if publication.Rates == nil || len(publication.Rates) != 0 {
+		t.Fatalf("tasas = %v; se esperaba un mapa vacío", publication.Rates)
+	}
+}
@@ -3,14 +3,19 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"precio-bcv-go/config" // Importar la configuración para acceder a las URL y números
+	"precio-bcv-go/tracing"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WhatsAppService maneja el envío de alertas vía WhatsApp a través de una API interna.
@@ -23,39 +28,62 @@ type WhatsAppService struct {
 // NewWhatsAppService crea e inicializa una nueva instancia de WhatsAppService.
 // Recibe la configuración de la aplicación para obtener la URL de la API y el número de destino.
 func NewWhatsAppService(appConfig *config.Config) *WhatsAppService {
+	return NewWhatsAppServiceTo(appConfig, appConfig.WhatsAppToNumber)
+}
+
+// NewWhatsAppServiceTo crea una instancia de WhatsAppService que envía a un número distinto
+// al de las alertas, por ejemplo el de un suscriptor de cambios de tasa.
+func NewWhatsAppServiceTo(appConfig *config.Config, toNumber string) *WhatsAppService {
 	// Puedes configurar el cliente HTTP aquí, por ejemplo, con un timeout.
 	httpClient := &http.Client{Timeout: 10 * time.Second}
 
 	return &WhatsAppService{
 		apiURL:   appConfig.WhatsAppAPIURL,
-		toNumber: appConfig.WhatsAppToNumber,
+		toNumber: toNumber,
 		client:   httpClient,
 	}
 }
 
+// Name retorna el nombre del canal.
+func (ws *WhatsAppService) Name() string {
+	return "whatsapp"
+}
+
 // SendAlert envía un mensaje de alerta a través de la API interna de WhatsApp.
 // Retorna un error si la solicitud falla o la API devuelve un estado no exitoso.
-func (ws *WhatsAppService) SendAlert(message string) error {
+// El envío queda en un span propio, cuyo contexto de traza viaja a la API en el encabezado "traceparent".
+func (ws *WhatsAppService) SendAlert(alertContext context.Context, kind NotificationKind, message string) error {
+	alertContext, sendSpan := tracing.Tracer().Start(alertContext, "whatsapp.send_alert", trace.WithSpanKind(trace.SpanKindClient))
+	defer sendSpan.End()
+
+	sendErr := ws.sendAlert(alertContext, message)
+	tracing.RecordError(sendSpan, sendErr)
+	return sendErr
+}
+
+// sendAlert hace la solicitud a la API de WhatsApp.
+func (ws *WhatsAppService) sendAlert(alertContext context.Context, message string) error {
 	if ws.apiURL == "" || ws.toNumber == "" {
-		log.Println("Advertencia: WhatsApp API URL o número de destino no configurados. No se puede enviar alerta.")
+		slog.WarnContext(alertContext, "WhatsApp API URL o número de destino no configurados. No se puede enviar alerta.")
 		return fmt.Errorf("configuración de WhatsApp API incompleta")
 	}
 
 	// Estructura del cuerpo de la solicitud JSON para tu API de WhatsApp
 	// ¡Ajusta esto según cómo espere los datos tu API interna!
 	requestBody, err := json.Marshal(map[string]string{
-		"tlf":      ws.toNumber,
+		"tlf":  ws.toNumber,
 		"body": message,
 	})
 	if err != nil {
 		return fmt.Errorf("error al serializar cuerpo de la solicitud de WhatsApp: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", ws.apiURL + "/send-text", bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(alertContext, "POST", ws.apiURL+"/send-text", bytes.NewBuffer(requestBody))
 	if err != nil {
 		return fmt.Errorf("error al crear solicitud HTTP para WhatsApp API: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	tracing.InjectHeaders(alertContext, req.Header)
 
 	resp, err := ws.client.Do(req)
 	if err != nil {
@@ -63,11 +91,12 @@ func (ws *WhatsAppService) SendAlert(message string) error {
 	}
 	defer resp.Body.Close()
 
+	trace.SpanFromContext(alertContext).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 	if resp.StatusCode != http.StatusOK {
-		responseBody, _ := ioutil.ReadAll(resp.Body)
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponseBytes))
 		return fmt.Errorf("error al enviar alerta por WhatsApp. Código de estado: %d, Respuesta: %s", resp.StatusCode, string(responseBody))
 	}
 
-	log.Println("Alerta de WhatsApp enviada exitosamente.")
+	slog.InfoContext(alertContext, "Alerta de WhatsApp enviada exitosamente.", "to", ws.toNumber)
 	return nil
 }
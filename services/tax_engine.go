@@ -0,0 +1,154 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"precio-bcv-go/config"
+	"precio-bcv-go/models"
+	"precio-bcv-go/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultTaxProfile es el perfil usado cuando un plan no indica ninguno: IVA reducido del 8%.
+const DefaultTaxProfile = "iva_8"
+
+// ErrUnknownTaxProfile indica que se pidió un perfil de impuestos que no está definido.
+var ErrUnknownTaxProfile = errors.New("perfil de impuestos desconocido")
+
+// Impuestos venezolanos usados por los perfiles incluidos.
+var (
+	ivaGeneralTax = models.TaxRule{Code: "IVA", Name: "IVA general", Percent: decimal.NewFromInt(16)}
+	ivaReducedTax = models.TaxRule{Code: "IVA", Name: "IVA reducido", Percent: decimal.NewFromInt(8)}
+	igtfTax       = models.TaxRule{Code: "IGTF", Name: "Impuesto a las Grandes Transacciones Financieras", Percent: decimal.NewFromInt(3), Compound: true}
+)
+
+// defaultTaxProfiles son los perfiles disponibles cuando no se configura TAX_PROFILES_FILE.
+// Los perfiles con "_igtf" aplican además el IGTF, para pagos en divisas.
+var defaultTaxProfiles = []models.TaxProfile{
+	{Name: "iva_16", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{ivaGeneralTax}}}},
+	{Name: "iva_8", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{ivaReducedTax}}}},
+	{Name: "iva_16_igtf", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{ivaGeneralTax, igtfTax}}}},
+	{Name: "iva_8_igtf", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{ivaReducedTax, igtfTax}}}},
+	{Name: "exempt", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{}}}},
+	{Name: "exempt_igtf", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{igtfTax}}}},
+}
+
+// oneHundred convierte los porcentajes de los impuestos en fracciones.
+var oneHundred = decimal.NewFromInt(100)
+
+// datedTaxVersion es una versión de perfil con su fecha de vigencia ya interpretada.
+type datedTaxVersion struct {
+	effectiveFrom time.Time
+	taxes         []models.TaxRule
+}
+
+// TaxEngine calcula impuestos según perfiles con nombre. Cada perfil puede tener varias versiones
+// con fecha de vigencia, de modo que un cambio de alícuota por decreto solo requiere agregar una versión.
+type TaxEngine struct {
+	profiles     map[string][]datedTaxVersion // Versiones ordenadas por fecha de vigencia ascendente.
+	roundingMode utils.RoundingMode           // Regla con la que cada monto se redondea a céntimos.
+}
+
+// NewTaxEngine crea el motor de impuestos con los perfiles de TAX_PROFILES_FILE, o con los incluidos si no está configurado.
+func NewTaxEngine(appConfig *config.Config) (*TaxEngine, error) {
+	taxProfiles := defaultTaxProfiles
+	if appConfig.TaxProfilesFile != "" {
+		fileContent, readErr := os.ReadFile(appConfig.TaxProfilesFile)
+		if readErr != nil {
+			return nil, fmt.Errorf("error al leer el archivo de perfiles de impuestos '%s': %w", appConfig.TaxProfilesFile, readErr)
+		}
+		taxProfiles = nil
+		if unmarshalErr := json.Unmarshal(fileContent, &taxProfiles); unmarshalErr != nil {
+			return nil, fmt.Errorf("error al interpretar el archivo de perfiles de impuestos '%s': %w", appConfig.TaxProfilesFile, unmarshalErr)
+		}
+	}
+	return NewTaxEngineWith(taxProfiles, appConfig.MoneyRounding)
+}
+
+// NewTaxEngineWith crea el motor de impuestos con los perfiles indicados, redondeando los montos con 'roundingMode'.
+func NewTaxEngineWith(taxProfiles []models.TaxProfile, roundingMode utils.RoundingMode) (*TaxEngine, error) {
+	engineProfiles := make(map[string][]datedTaxVersion, len(taxProfiles))
+	for _, taxProfile := range taxProfiles {
+		if taxProfile.Name == "" || len(taxProfile.Versions) == 0 {
+			return nil, fmt.Errorf("perfil de impuestos inválido: requiere nombre y al menos una versión")
+		}
+		profileVersions := make([]datedTaxVersion, 0, len(taxProfile.Versions))
+		for _, profileVersion := range taxProfile.Versions {
+			var effectiveFrom time.Time
+			if profileVersion.EffectiveFrom != "" {
+				parsedDate, parseErr := time.ParseInLocation("2006-01-02", profileVersion.EffectiveFrom, time.Local)
+				if parseErr != nil {
+					return nil, fmt.Errorf("fecha de vigencia inválida '%s' en el perfil '%s': %w", profileVersion.EffectiveFrom, taxProfile.Name, parseErr)
+				}
+				effectiveFrom = parsedDate
+			}
+			profileVersions = append(profileVersions, datedTaxVersion{effectiveFrom: effectiveFrom, taxes: profileVersion.Taxes})
+		}
+		sort.Slice(profileVersions, func(i, j int) bool {
+			return profileVersions[i].effectiveFrom.Before(profileVersions[j].effectiveFrom)
+		})
+		engineProfiles[taxProfile.Name] = profileVersions
+	}
+
+	slog.Info("Motor de impuestos inicializado", "profiles", len(engineProfiles), "rounding", roundingMode)
+	return &TaxEngine{profiles: engineProfiles, roundingMode: roundingMode}, nil
+}
+
+// RoundAmount redondea un monto a céntimos con la regla del motor.
+func (engine *TaxEngine) RoundAmount(amount decimal.Decimal) decimal.Decimal {
+	return utils.RoundMoney(amount, engine.roundingMode)
+}
+
+// Calculate aplica el perfil de impuestos vigente en 'onDate' a 'baseAmount' y retorna el desglose.
+// Cada monto se redondea a 2 decimales; el total es la suma exacta de la base y los impuestos redondeados,
+// por lo que siempre coincide con el desglose.
+func (engine *TaxEngine) Calculate(profileName string, baseAmount decimal.Decimal, onDate time.Time) (models.TaxBreakdown, error) {
+	profileVersions, exists := engine.profiles[profileName]
+	if !exists {
+		return models.TaxBreakdown{}, fmt.Errorf("%w: '%s'", ErrUnknownTaxProfile, profileName)
+	}
+
+	// La versión vigente es la última cuya fecha no es posterior a 'onDate'.
+	var effectiveTaxes []models.TaxRule
+	versionFound := false
+	for _, profileVersion := range profileVersions {
+		if profileVersion.effectiveFrom.After(onDate) {
+			break
+		}
+		effectiveTaxes = profileVersion.taxes
+		versionFound = true
+	}
+	if !versionFound {
+		return models.TaxBreakdown{}, fmt.Errorf("%w: '%s' no tiene una versión vigente el %s", ErrUnknownTaxProfile, profileName, onDate.Format("2006-01-02"))
+	}
+
+	roundedBase := engine.RoundAmount(baseAmount)
+	taxBreakdown := models.TaxBreakdown{
+		TaxProfile: profileName,
+		Base:       roundedBase,
+		Taxes:      make([]models.TaxLineItem, 0, len(effectiveTaxes)),
+		Total:      roundedBase,
+	}
+	for _, taxRule := range effectiveTaxes {
+		taxableAmount := roundedBase
+		if taxRule.Compound {
+			taxableAmount = taxBreakdown.Total
+		}
+		taxAmount := engine.RoundAmount(taxableAmount.Mul(taxRule.Percent).Div(oneHundred))
+		taxBreakdown.Taxes = append(taxBreakdown.Taxes, models.TaxLineItem{
+			Code:    taxRule.Code,
+			Name:    taxRule.Name,
+			Percent: taxRule.Percent,
+			Amount:  taxAmount,
+		})
+		taxBreakdown.Total = taxBreakdown.Total.Add(taxAmount)
+	}
+	return taxBreakdown, nil
+}
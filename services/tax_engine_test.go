@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"precio-bcv-go/models"
+	"precio-bcv-go/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+// taxRule arma un impuesto de prueba con el porcentaje indicado como texto (ej. "16").
+func taxRule(code, percentText string, compound bool) models.TaxRule {
+	return models.TaxRule{Code: code, Name: code, Percent: decimal.RequireFromString(percentText), Compound: compound}
+}
+
+func TestTaxEngineCalculate(t *testing.T) {
+	testProfiles := append([]models.TaxProfile{
+		// Versiones desordenadas a propósito: el motor las ordena por fecha de vigencia.
+		{Name: "decreto", Versions: []models.TaxProfileVersion{
+			{EffectiveFrom: "2025-06-01", Taxes: []models.TaxRule{taxRule("IVA", "8", false)}},
+			{EffectiveFrom: "2024-01-01", Taxes: []models.TaxRule{taxRule("IVA", "16", false)}},
+		}},
+		{Name: "tasa_empate", Versions: []models.TaxProfileVersion{{Taxes: []models.TaxRule{taxRule("IVA", "1.25", false)}}}},
+	}, defaultTaxProfiles...)
+	onDate := func(dateText string) time.Time {
+		parsedDate, parseErr := time.ParseInLocation("2006-01-02", dateText, time.Local)
+		if parseErr != nil {
+			t.Fatal(parseErr)
+		}
+		return parsedDate
+	}
+
+	testCases := []struct {
+		name           string
+		profileName    string
+		baseAmount     string
+		onDate         time.Time
+		roundingMode   utils.RoundingMode
+		expectedTaxes  []string // Monto de cada impuesto, en orden.
+		expectedTotal  string
+		expectNotFound bool
+	}{
+		{name: "IVA general", profileName: "iva_16", baseAmount: "100", expectedTaxes: []string{"16"}, expectedTotal: "116"},
+		{name: "exento", profileName: "exempt", baseAmount: "100", expectedTaxes: []string{}, expectedTotal: "100"},
+		// El IGTF es compuesto: se calcula sobre la base más el IVA (3% de 116).
+		{name: "IGTF compuesto sobre base e IVA", profileName: "iva_16_igtf", baseAmount: "100", expectedTaxes: []string{"16", "3.48"}, expectedTotal: "119.48"},
+		{name: "IGTF sin IVA", profileName: "exempt_igtf", baseAmount: "100", expectedTaxes: []string{"3"}, expectedTotal: "103"},
+		{name: "versión anterior al decreto", profileName: "decreto", baseAmount: "100", onDate: onDate("2025-05-31"), expectedTaxes: []string{"16"}, expectedTotal: "116"},
+		{name: "versión desde el día del decreto", profileName: "decreto", baseAmount: "100", onDate: onDate("2025-06-01"), expectedTaxes: []string{"8"}, expectedTotal: "108"},
+		{name: "sin versión vigente", profileName: "decreto", baseAmount: "100", onDate: onDate("2023-12-31"), expectNotFound: true},
+		{name: "perfil desconocido", profileName: "no_existe", baseAmount: "100", expectNotFound: true},
+		{name: "base con empate half_up", profileName: "exempt", baseAmount: "0.125", roundingMode: utils.RoundHalfUp, expectedTaxes: []string{}, expectedTotal: "0.13"},
+		{name: "base con empate half_even", profileName: "exempt", baseAmount: "0.125", roundingMode: utils.RoundHalfEven, expectedTaxes: []string{}, expectedTotal: "0.12"},
+		{name: "impuesto con empate half_up", profileName: "tasa_empate", baseAmount: "10", roundingMode: utils.RoundHalfUp, expectedTaxes: []string{"0.13"}, expectedTotal: "10.13"},
+		{name: "impuesto con empate half_even", profileName: "tasa_empate", baseAmount: "10", roundingMode: utils.RoundHalfEven, expectedTaxes: []string{"0.12"}, expectedTotal: "10.12"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			roundingMode := testCase.roundingMode
+			if roundingMode == "" {
+				roundingMode = utils.RoundHalfUp
+			}
+			taxEngine, engineErr := NewTaxEngineWith(testProfiles, roundingMode)
+			if engineErr != nil {
+				t.Fatal(engineErr)
+			}
+			calculationDate := testCase.onDate
+			if calculationDate.IsZero() {
+				calculationDate = time.Now()
+			}
+
+			taxBreakdown, calculateErr := taxEngine.Calculate(testCase.profileName, decimal.RequireFromString(testCase.baseAmount), calculationDate)
+			if testCase.expectNotFound {
+				if !errors.Is(calculateErr, ErrUnknownTaxProfile) {
+					t.Fatalf("Calculate retornó %+v, %v; se esperaba ErrUnknownTaxProfile", taxBreakdown, calculateErr)
+				}
+				return
+			}
+			if calculateErr != nil {
+				t.Fatalf("Calculate retornó un error: %v", calculateErr)
+			}
+			if len(taxBreakdown.Taxes) != len(testCase.expectedTaxes) {
+				t.Fatalf("impuestos = %+v; se esperaban %v", taxBreakdown.Taxes, testCase.expectedTaxes)
+			}
+			for taxIndex, expectedAmount := range testCase.expectedTaxes {
+				if !taxBreakdown.Taxes[taxIndex].Amount.Equal(decimal.RequireFromString(expectedAmount)) {
+					t.Fatalf("impuesto %s = %s; se esperaba %s", taxBreakdown.Taxes[taxIndex].Code, taxBreakdown.Taxes[taxIndex].Amount, expectedAmount)
+				}
+			}
+			if !taxBreakdown.Total.Equal(decimal.RequireFromString(testCase.expectedTotal)) {
+				t.Fatalf("total = %s; se esperaba %s", taxBreakdown.Total, testCase.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestNewTaxEngineWithRejectsInvalidProfiles(t *testing.T) {
+	testCases := []struct {
+		name       string
+		taxProfile models.TaxProfile
+	}{
+		{name: "sin nombre", taxProfile: models.TaxProfile{Versions: []models.TaxProfileVersion{{}}}},
+		{name: "sin versiones", taxProfile: models.TaxProfile{Name: "vacio"}},
+		{name: "fecha de vigencia inválida", taxProfile: models.TaxProfile{Name: "fecha", Versions: []models.TaxProfileVersion{{EffectiveFrom: "01/06/2025"}}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, engineErr := NewTaxEngineWith([]models.TaxProfile{testCase.taxProfile}, utils.RoundHalfUp); engineErr == nil {
+				t.Fatal("NewTaxEngineWith no retornó un error")
+			}
+		})
+	}
+}
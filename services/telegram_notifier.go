@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"precio-bcv-go/config"
+)
+
+// telegramAPIBaseURL es la URL base de la API de bots de Telegram.
+const telegramAPIBaseURL = "https://api.telegram.org"
+
+// TelegramNotifier envía alertas a un chat mediante la API de bots de Telegram.
+type TelegramNotifier struct {
+	apiBaseURL string
+	botToken   string
+	chatID     string
+	client     *http.Client
+}
+
+// NewTelegramNotifier crea un canal de Telegram con el token del bot y el chat de destino configurados.
+func NewTelegramNotifier(appConfig *config.Config) *TelegramNotifier {
+	return &TelegramNotifier{
+		apiBaseURL: telegramAPIBaseURL,
+		botToken:   appConfig.TelegramBotToken,
+		chatID:     appConfig.TelegramChatID,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name retorna el nombre del canal.
+func (notifier *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// SendAlert envía el mensaje con el método sendMessage del bot.
+func (notifier *TelegramNotifier) SendAlert(alertContext context.Context, kind NotificationKind, message string) error {
+	sendMessageURL := fmt.Sprintf("%s/bot%s/sendMessage", notifier.apiBaseURL, notifier.botToken)
+	sendErr := postJSON(alertContext, notifier.client, sendMessageURL, map[string]string{
+		"chat_id": notifier.chatID,
+		"text":    message,
+	})
+	if sendErr != nil {
+		return fmt.Errorf("error al enviar alerta por Telegram: %w", sendErr)
+	}
+
+	slog.InfoContext(alertContext, "Alerta de Telegram enviada exitosamente.")
+	return nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"math"
+	"time"
+)
+
+// tokenBucket limita el ritmo de peticiones: se recarga a 'refillPerSecond' fichas por segundo hasta
+// 'capacity', y cada petición consume una ficha. No es seguro para uso concurrente; quien lo usa lo protege.
+type tokenBucket struct {
+	capacity        float64
+	refillPerSecond float64
+	availableTokens float64
+	lastRefill      time.Time
+}
+
+// newTokenBucket crea un bucket lleno con la capacidad y el ritmo de recarga indicados.
+func newTokenBucket(capacity int, refillPerSecond float64, currentTime time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity:        float64(capacity),
+		refillPerSecond: refillPerSecond,
+		availableTokens: float64(capacity),
+		lastRefill:      currentTime,
+	}
+}
+
+// take consume una ficha si hay disponible. Si no, retorna false y cuánto falta para la próxima ficha.
+func (bucket *tokenBucket) take(currentTime time.Time) (bool, time.Duration) {
+	elapsedSeconds := currentTime.Sub(bucket.lastRefill).Seconds()
+	if elapsedSeconds > 0 {
+		bucket.availableTokens = math.Min(bucket.capacity, bucket.availableTokens+elapsedSeconds*bucket.refillPerSecond)
+		bucket.lastRefill = currentTime
+	}
+
+	if bucket.availableTokens >= 1 {
+		bucket.availableTokens--
+		return true, 0
+	}
+	if bucket.refillPerSecond <= 0 {
+		return false, time.Minute
+	}
+	missingSeconds := (1 - bucket.availableTokens) / bucket.refillPerSecond
+	return false, time.Duration(math.Ceil(missingSeconds * float64(time.Second)))
+}
+
+// remaining retorna cuántas fichas enteras quedan, sin recargar.
+func (bucket *tokenBucket) remaining() int {
+	return int(bucket.availableTokens)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"precio-bcv-go/config"
+)
+
+// SlackNotifier envía alertas a un webhook entrante compatible con Slack (Slack, Mattermost, Rocket.Chat...).
+type SlackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackNotifier crea un canal de Slack con la URL del webhook configurada.
+func NewSlackNotifier(appConfig *config.Config) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: appConfig.SlackWebhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name retorna el nombre del canal.
+func (notifier *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// SendAlert envía el mensaje en el campo "text" que esperan los webhooks compatibles con Slack.
+func (notifier *SlackNotifier) SendAlert(alertContext context.Context, kind NotificationKind, message string) error {
+	if sendErr := postJSON(alertContext, notifier.client, notifier.webhookURL, map[string]string{"text": message}); sendErr != nil {
+		return fmt.Errorf("error al enviar alerta por Slack: %w", sendErr)
+	}
+
+	slog.InfoContext(alertContext, "Alerta de Slack enviada exitosamente.")
+	return nil
+}
+
+// WebhookNotifier envía alertas como JSON a un endpoint HTTP genérico.
+type WebhookNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewWebhookNotifier crea un canal de webhook genérico con la URL de alertas configurada.
+func NewWebhookNotifier(appConfig *config.Config) *WebhookNotifier {
+	return NewWebhookNotifierTo(appConfig.AlertWebhookURL)
+}
+
+// NewWebhookNotifierTo crea un canal de webhook genérico hacia la URL indicada.
+func NewWebhookNotifierTo(webhookURL string) *WebhookNotifier {
+	return &WebhookNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name retorna el nombre del canal.
+func (notifier *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// SendAlert envía un JSON con el tipo de mensaje, el mensaje, el origen y la fecha del envío.
+func (notifier *WebhookNotifier) SendAlert(alertContext context.Context, kind NotificationKind, message string) error {
+	sendErr := postJSON(alertContext, notifier.client, notifier.webhookURL, map[string]string{
+		"source":    "precio-bcv-go",
+		"kind":      string(kind),
+		"message":   message,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+	if sendErr != nil {
+		return fmt.Errorf("error al enviar alerta por webhook: %w", sendErr)
+	}
+
+	// La URL del webhook puede llevar un secreto en la ruta o en la consulta, por lo que no se registra.
+	slog.InfoContext(alertContext, "Alerta de webhook enviada exitosamente.")
+	return nil
+}
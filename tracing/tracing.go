@@ -0,0 +1,95 @@
+// Package tracing configura las trazas de OpenTelemetry de la aplicación: el proveedor de spans, el exportador
+// OTLP y la propagación del contexto de traza (W3C traceparent) en las peticiones HTTP entrantes y salientes.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"precio-bcv-go/config"
+)
+
+// instrumentationName identifica a los spans creados por esta aplicación.
+const instrumentationName = "precio-bcv-go"
+
+// Tracer retorna el tracer de la aplicación. Mientras no se instale un proveedor, los spans no se registran,
+// pero el contexto de traza recibido se sigue propagando.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup instala el propagador W3C y, si TRACING_EXPORTER es "otlp", un proveedor que exporta los spans por OTLP/HTTP.
+// Retorna la función que vacía y cierra el exportador; debe llamarse al apagar la aplicación.
+func Setup(setupContext context.Context, appConfig *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if appConfig.TracingExporter != "otlp" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporterOptions []otlptracehttp.Option
+	if appConfig.OTLPEndpoint != "" {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithEndpoint(appConfig.OTLPEndpoint))
+	}
+	if appConfig.OTLPInsecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+	spanExporter, exporterErr := otlptracehttp.New(setupContext, exporterOptions...)
+	if exporterErr != nil {
+		return nil, fmt.Errorf("error al crear el exportador OTLP: %w", exporterErr)
+	}
+
+	tracerProvider, installErr := Install(spanExporter, appConfig.TracingServiceName, appConfig.TracingSampleRatio)
+	if installErr != nil {
+		return nil, installErr
+	}
+	return tracerProvider.Shutdown, nil
+}
+
+// Install instala como proveedor global uno que envía los spans a 'spanExporter', muestreando la fracción
+// 'sampleRatio' de las trazas nuevas (las que llegan con un padre respetan su decisión de muestreo).
+// Además del exportador OTLP de Setup, acepta por ejemplo un tracetest.InMemoryExporter en las pruebas;
+// en ese caso conviene llamar a ForceFlush del proveedor retornado antes de leer los spans.
+func Install(spanExporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) (*sdktrace.TracerProvider, error) {
+	serviceResource, resourceErr := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if resourceErr != nil {
+		return nil, fmt.Errorf("error al crear el recurso de trazas: %w", resourceErr)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tracerProvider, nil
+}
+
+// RecordError marca el span como fallido con 'spanErr'. No hace nada si 'spanErr' es nil.
+func RecordError(span trace.Span, spanErr error) {
+	if spanErr == nil {
+		return
+	}
+	span.RecordError(spanErr)
+	span.SetStatus(codes.Error, spanErr.Error())
+}
+
+// InjectHeaders agrega a los encabezados de una petición saliente el contexto de traza de 'requestContext'.
+func InjectHeaders(requestContext context.Context, requestHeader http.Header) {
+	otel.GetTextMapPropagator().Inject(requestContext, propagation.HeaderCarrier(requestHeader))
+}
+
+// ExtractHeaders retorna 'requestContext' con el contexto de traza recibido en los encabezados de una petición entrante.
+func ExtractHeaders(requestContext context.Context, requestHeader http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(requestContext, propagation.HeaderCarrier(requestHeader))
+}
@@ -2,18 +2,45 @@ package utils
 
 import (
 	"fmt"
-	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Decimales de los montos en bolívares o divisas, y de las tasas de cambio (el BCV publica 8).
+const (
+	MoneyPlaces = 2
+	RatePlaces  = 8
 )
 
-// FormatFloat retorna un número con 2 decimales
-func FormatFloat(f float64) float64 {
-	formateNum := fmt.Sprintf("%.2f", f) // Original: formateNum
+// RoundingMode es la regla con la que se redondean los montos y las tasas calculadas.
+type RoundingMode string
 
-	convertNum, err := strconv.ParseFloat(formateNum, 64) // Original: convertNum, err
+// Reglas de redondeo soportadas. Ambas solo difieren en los empates (ej. 0,125 a 2 decimales).
+const (
+	RoundHalfUp   RoundingMode = "half_up"   // El empate se aleja de cero: 0,125 → 0,13.
+	RoundHalfEven RoundingMode = "half_even" // El empate va al dígito par ("redondeo bancario"): 0,125 → 0,12.
+)
 
-	if err != nil {
-		fmt.Printf("Error al formatear float: %v\n", err)
-		return 0.0
+// ParseRoundingMode convierte un nombre de regla ("half_up" o "half_even") en un RoundingMode.
+func ParseRoundingMode(modeName string) (RoundingMode, error) {
+	switch roundingMode := RoundingMode(strings.ToLower(strings.TrimSpace(modeName))); roundingMode {
+	case RoundHalfUp, RoundHalfEven:
+		return roundingMode, nil
+	default:
+		return "", fmt.Errorf("regla de redondeo inválida '%s' (use half_up o half_even)", modeName)
 	}
-	return convertNum
-}
\ No newline at end of file
+}
+
+// Round redondea 'value' a 'places' decimales con la regla indicada.
+func Round(value decimal.Decimal, places int32, roundingMode RoundingMode) decimal.Decimal {
+	if roundingMode == RoundHalfEven {
+		return value.RoundBank(places)
+	}
+	return value.Round(places)
+}
+
+// RoundMoney redondea un monto a MoneyPlaces decimales con la regla indicada.
+func RoundMoney(amount decimal.Decimal, roundingMode RoundingMode) decimal.Decimal {
+	return Round(amount, MoneyPlaces, roundingMode)
+}